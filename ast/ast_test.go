@@ -1,6 +1,7 @@
 package ast_test
 
 import (
+	"bytes"
 	"testing"
 
 	"monkey/ast"
@@ -39,3 +40,38 @@ func (s *Suite) TestString() {
 
 	s.Require().Equal("let myVar = anotherVar;", program.String())
 }
+
+func (s *Suite) TestFprint() {
+	program := &ast.Program{
+		Statements: []ast.Statement{
+			&ast.LetStatement{
+				Token: token.Token{Type: token.LET, Literal: "let"},
+				Name: &ast.Identifier{
+					Token: token.Token{Type: token.IDENT, Literal: "x"},
+					Value: "x",
+				},
+				Value: &ast.ArrayLiteral{
+					Token: token.Token{Type: token.LBRACKET, Literal: "["},
+					Elements: []ast.Expression{
+						&ast.IntegerLiteral{Token: token.Token{Type: token.INT, Literal: "1"}, Value: 1},
+					},
+				},
+			},
+		},
+	}
+
+	var out bytes.Buffer
+	s.Require().NoError(ast.Fprint(&out, program))
+
+	expected := `Program
+  Statements: [1]
+    0: LetStatement
+      Name: Identifier
+        Value: "x"
+      Value: ArrayLiteral
+        Elements: [1]
+          0: IntegerLiteral
+            Value: 1
+`
+	s.Require().Equal(expected, out.String())
+}
@@ -0,0 +1,91 @@
+package ast
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"monkey/token"
+)
+
+var (
+	nodeType  = reflect.TypeOf((*Node)(nil)).Elem()
+	tokenType = reflect.TypeOf(token.Token{})
+)
+
+// Fprint writes the structure of the syntax tree rooted at node, one field per line and indented by depth.
+// Tokens are left out since they are already reflected by the node types and positions.
+func Fprint(w io.Writer, node Node) error {
+	p := &printer{w: w}
+	p.printValue(reflect.ValueOf(node), 0)
+	return p.err
+}
+
+type printer struct {
+	w   io.Writer
+	err error
+}
+
+func (p *printer) printf(depth int, format string, args ...interface{}) {
+	if p.err != nil {
+		return
+	}
+	_, p.err = fmt.Fprintf(p.w, strings.Repeat("  ", depth)+format, args...)
+}
+
+// printValue prints the value on the current line and its children on the following lines
+func (p *printer) printValue(v reflect.Value, depth int) {
+	if (v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer) && v.IsNil() || !v.IsValid() {
+		p.printf(0, "nil\n")
+		return
+	}
+
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+
+	if s, ok := v.Interface().(fmt.Stringer); ok && !v.Type().Implements(nodeType) {
+		// values such as big integers print themselves
+		p.printf(0, "%s\n", s)
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if node, ok := v.Interface().(Node); ok && node.Pos().IsValid() {
+			p.printf(0, "%s %s-%s\n", v.Elem().Type().Name(), node.Pos(), node.End())
+		} else {
+			p.printf(0, "%s\n", v.Elem().Type().Name())
+		}
+		p.printFields(v.Elem(), depth+1)
+	case reflect.Struct:
+		p.printf(0, "%s\n", v.Type().Name())
+		p.printFields(v, depth+1)
+	case reflect.Slice:
+		p.printf(0, "[%d]\n", v.Len())
+		for i := 0; i < v.Len(); i++ {
+			p.printf(depth+1, "%d: ", i)
+			p.printValue(v.Index(i), depth+1)
+		}
+	case reflect.String:
+		p.printf(0, "%q\n", v.String())
+	default:
+		p.printf(0, "%v\n", v.Interface())
+	}
+}
+
+func (p *printer) printFields(v reflect.Value, depth int) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() || field.Type == tokenType {
+			continue
+		}
+		if field.Type.Kind() == reflect.Pointer && !field.Type.Implements(nodeType) && v.Field(i).IsNil() {
+			// optional values that are not nodes, e.g. IntegerLiteral.Big, are only printed when set
+			continue
+		}
+		p.printf(depth, "%s: ", field.Name)
+		p.printValue(v.Field(i), depth)
+	}
+}
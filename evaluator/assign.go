@@ -0,0 +1,118 @@
+package evaluator
+
+import (
+	"strings"
+
+	"monkey/ast"
+	"monkey/object"
+)
+
+func evalAssignExpression(node *ast.AssignExpression, env *object.Environment) object.Object {
+	switch target := node.Target.(type) {
+	case *ast.Identifier:
+		return evalIdentifierAssignment(node, target, env)
+	case *ast.IndexExpression:
+		return evalIndexAssignment(node, target, env)
+	default:
+		return newError("cannot assign to %s", node.Target.String())
+	}
+}
+
+// evalIdentifierAssignment updates the variable in the scope where it was defined, which may be the
+// scope of an enclosing function
+func evalIdentifierAssignment(node *ast.AssignExpression, target *ast.Identifier, env *object.Environment) object.Object {
+	if env.IsConst(target.Value) {
+		// checked before evaluating the value so it has no side effects
+		return newError("cannot assign to constant %s", target.Value)
+	}
+
+	var current object.Object
+	if node.Operator != "=" {
+		var ok bool
+		if current, ok = env.Get(target.Value); !ok {
+			return newError("identifier not found: %s", target.Value)
+		}
+	}
+
+	val := evalAssignedValue(node, current, env)
+	if interrupts(val) {
+		return val
+	}
+
+	switch err := env.Assign(target.Value, val); err {
+	case object.ErrUndefined:
+		return newError("cannot assign to undefined variable %s", target.Value)
+	case object.ErrConstant:
+		return newError("cannot assign to constant %s", target.Value)
+	}
+	return val
+}
+
+// evalIndexAssignment stores the value in an element of an array or hash, arrays are indexed like in
+// index expressions but cannot grow by assignment
+func evalIndexAssignment(node *ast.AssignExpression, target *ast.IndexExpression, env *object.Environment) object.Object {
+	left := Eval(target.Left, env)
+	if interrupts(left) {
+		return left
+	}
+	index := Eval(target.Index, env)
+	if interrupts(index) {
+		return index
+	}
+
+	switch left := left.(type) {
+	case *object.Array:
+		integer, ok := index.(*object.Integer)
+		if !ok {
+			return newError("array index must be INTEGER, got %s", index.Type())
+		}
+		idx := integer.Value
+		length := int64(len(left.Elements))
+		if idx < 0 {
+			idx += length
+		}
+		if idx < 0 || idx >= length {
+			return newError("index out of range: %d with length %d", integer.Value, length)
+		}
+
+		val := evalAssignedValue(node, left.Elements[idx], env)
+		if interrupts(val) {
+			return val
+		}
+		left.Elements[idx] = val
+		return val
+	case *object.Hash:
+		key, ok := index.(object.Hashable)
+		if !ok {
+			return newError("unusable as hash key: %s", index.Type())
+		}
+
+		var current object.Object
+		if node.Operator != "=" {
+			pair, ok := left.Get(key.HashKey())
+			if !ok {
+				return newError("key not found: %s", index.Inspect())
+			}
+			current = pair.Value
+		}
+
+		val := evalAssignedValue(node, current, env)
+		if interrupts(val) {
+			return val
+		}
+		left.Set(key.HashKey(), object.HashPair{Key: index, Value: val})
+		return val
+	default:
+		return newError("index assignment not supported: %s", left.Type())
+	}
+}
+
+// evalAssignedValue evaluates the right-hand side of an assignment, for compound operators such as += it
+// is combined with the current value
+func evalAssignedValue(node *ast.AssignExpression, current object.Object, env *object.Environment) object.Object {
+	val := Eval(node.Value, env)
+	if interrupts(val) || node.Operator == "=" {
+		return val
+	}
+	return evalInfixExpression(strings.TrimSuffix(node.Operator, "="), current, val, env)
+}
@@ -0,0 +1,243 @@
+package evaluator
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+
+	"monkey/object"
+)
+
+var builtins = map[string]*object.Builtin{
+	"len":   {Name: "len", Fn: builtinLen},
+	"puts":  {Name: "puts", EnvFn: builtinPuts},
+	"first": {Name: "first", Fn: builtinFirst},
+	"last":  {Name: "last", Fn: builtinLast},
+	"rest":  {Name: "rest", Fn: builtinRest},
+	"push":  {Name: "push", Fn: builtinPush},
+	"type":  {Name: "type", Fn: builtinType},
+	"str":   {Name: "str", Fn: builtinStr},
+	"int":   {Name: "int", Fn: builtinInt},
+	"float": {Name: "float", Fn: builtinFloat},
+	"range": {Name: "range", Fn: builtinRange},
+}
+
+// BuiltinNames returns the sorted names of the builtin functions
+func BuiltinNames() []string {
+	names := make([]string, 0, len(builtins))
+	for name := range builtins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupBuiltin returns the builtin function with the given name
+func LookupBuiltin(name string) (*object.Builtin, bool) {
+	builtin, ok := builtins[name]
+	return builtin, ok
+}
+
+func builtinLen(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return wrongNumberOfArguments(len(args), 1)
+	}
+
+	switch arg := args[0].(type) {
+	case *object.String:
+		return &object.Integer{Value: int64(utf8.RuneCountInString(arg.Value))}
+	case *object.Array:
+		return &object.Integer{Value: int64(len(arg.Elements))}
+	case *object.Hash:
+		return &object.Integer{Value: int64(arg.Len())}
+	case *object.Range:
+		return newInteger(new(big.Int).SetUint64(arg.Len()))
+	default:
+		return newError("argument to `len` not supported, got %s", args[0].Type())
+	}
+}
+
+// builtinPuts writes every argument on a line of its own to the output of the environment
+func builtinPuts(env *object.Environment, args ...object.Object) object.Object {
+	for _, arg := range args {
+		fmt.Fprintln(env.Output(), arg.Inspect())
+	}
+	return NULL
+}
+
+func builtinFirst(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return wrongNumberOfArguments(len(args), 1)
+	}
+
+	array, ok := args[0].(*object.Array)
+	if !ok {
+		return newError("argument to `first` must be ARRAY, got %s", args[0].Type())
+	}
+
+	if len(array.Elements) > 0 {
+		return array.Elements[0]
+	}
+	return NULL
+}
+
+func builtinLast(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return wrongNumberOfArguments(len(args), 1)
+	}
+
+	array, ok := args[0].(*object.Array)
+	if !ok {
+		return newError("argument to `last` must be ARRAY, got %s", args[0].Type())
+	}
+
+	if length := len(array.Elements); length > 0 {
+		return array.Elements[length-1]
+	}
+	return NULL
+}
+
+// builtinRest returns a new array containing every element but the first
+func builtinRest(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return wrongNumberOfArguments(len(args), 1)
+	}
+
+	array, ok := args[0].(*object.Array)
+	if !ok {
+		return newError("argument to `rest` must be ARRAY, got %s", args[0].Type())
+	}
+
+	length := len(array.Elements)
+	if length == 0 {
+		return NULL
+	}
+
+	elements := make([]object.Object, length-1)
+	copy(elements, array.Elements[1:])
+	return &object.Array{Elements: elements}
+}
+
+// builtinPush returns a new array with the element appended, the original array is left untouched
+func builtinPush(args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return wrongNumberOfArguments(len(args), 2)
+	}
+
+	array, ok := args[0].(*object.Array)
+	if !ok {
+		return newError("argument to `push` must be ARRAY, got %s", args[0].Type())
+	}
+
+	length := len(array.Elements)
+	elements := make([]object.Object, length+1)
+	copy(elements, array.Elements)
+	elements[length] = args[1]
+
+	return &object.Array{Elements: elements}
+}
+
+func builtinType(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return wrongNumberOfArguments(len(args), 1)
+	}
+
+	return &object.String{Value: string(args[0].Type())}
+}
+
+func builtinStr(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return wrongNumberOfArguments(len(args), 1)
+	}
+
+	if str, ok := args[0].(*object.String); ok {
+		return str
+	}
+	return &object.String{Value: args[0].Inspect()}
+}
+
+func builtinInt(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return wrongNumberOfArguments(len(args), 1)
+	}
+
+	switch arg := args[0].(type) {
+	case *object.Integer, *object.BigInt:
+		return arg
+	case *object.Float:
+		// truncate towards zero, the range check also rejects NaN
+		if !(arg.Value >= -(1<<63) && arg.Value < 1<<63) {
+			return newError("could not convert %s to INTEGER", arg.Inspect())
+		}
+		return &object.Integer{Value: int64(arg.Value)}
+	case *object.Boolean:
+		if arg.Value {
+			return &object.Integer{Value: 1}
+		}
+		return &object.Integer{Value: 0}
+	case *object.String:
+		value, err := strconv.ParseInt(arg.Value, 0, 64)
+		if err != nil {
+			return newError("could not convert %q to INTEGER", arg.Value)
+		}
+		return &object.Integer{Value: value}
+	default:
+		return newError("argument to `int` not supported, got %s", args[0].Type())
+	}
+}
+
+func builtinFloat(args ...object.Object) object.Object {
+	if len(args) != 1 {
+		return wrongNumberOfArguments(len(args), 1)
+	}
+
+	switch arg := args[0].(type) {
+	case *object.Float:
+		return arg
+	case *object.Integer, *object.BigInt:
+		return &object.Float{Value: toFloat(arg)}
+	case *object.String:
+		value, err := strconv.ParseFloat(arg.Value, 64)
+		if err != nil {
+			return newError("could not convert %q to FLOAT", arg.Value)
+		}
+		return &object.Float{Value: value}
+	default:
+		return newError("argument to `float` not supported, got %s", args[0].Type())
+	}
+}
+
+// builtinRange returns the range of integers up to the end, taking the start and step from the leading
+// arguments when there are more than one: range(end), range(start, end) or range(start, end, step)
+func builtinRange(args ...object.Object) object.Object {
+	if len(args) < 1 || len(args) > 3 {
+		return newError("wrong number of arguments: want=1 to 3, got=%d", len(args))
+	}
+
+	bounds := make([]int64, len(args))
+	for i, arg := range args {
+		integer, ok := arg.(*object.Integer)
+		if !ok {
+			return newError("arguments to `range` must be INTEGER, got %s", arg.Type())
+		}
+		bounds[i] = integer.Value
+	}
+
+	switch len(bounds) {
+	case 1:
+		return &object.Range{End: bounds[0], Step: 1}
+	case 2:
+		return &object.Range{Start: bounds[0], End: bounds[1], Step: 1}
+	}
+
+	if bounds[2] == 0 {
+		return newError("range step must not be zero")
+	}
+	return &object.Range{Start: bounds[0], End: bounds[1], Step: bounds[2]}
+}
+
+func wrongNumberOfArguments(got, want int) *object.Error {
+	return newError("wrong number of arguments: want=%d, got=%d", want, got)
+}
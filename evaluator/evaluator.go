@@ -2,6 +2,8 @@ package evaluator
 
 import (
 	"fmt"
+	"math"
+	"math/big"
 	"monkey/ast"
 	"monkey/object"
 )
@@ -10,8 +12,14 @@ var (
 	NULL  = &object.Null{}
 	TRUE  = &object.Boolean{Value: true}
 	FALSE = &object.Boolean{Value: false}
+
+	BREAK    = &object.Break{}
+	CONTINUE = &object.Continue{}
 )
 
+// maxCallDepth is the number of nested function calls after which a call fails
+const maxCallDepth = 10000
+
 func Eval(node ast.Node, env *object.Environment) object.Object {
 	switch node := node.(type) {
 	// Statements
@@ -22,41 +30,59 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 
 	// Expressions
 	case *ast.IntegerLiteral:
-		return &object.Integer{Value: node.Value}
+		return evalIntegerLiteral(node, env)
+	case *ast.FloatLiteral:
+		return &object.Float{Value: node.Value}
 	case *ast.BooleanLiteral:
 		return nativeBoolToBooleanObject(node.Value)
 	case *ast.PrefixExpression:
+		if lit, ok := node.Right.(*ast.IntegerLiteral); ok && lit.Big != nil && node.Operator == "-" {
+			return evalNegatedIntegerLiteral(lit, env)
+		}
 		right := Eval(node.Right, env)
-		if isError(right) {
+		if interrupts(right) {
 			return right
 		}
-		return evalPrefixExpression(node.Operator, right)
+		return evalPrefixExpression(node.Operator, right, env)
 	case *ast.InfixExpression:
 		left := Eval(node.Left, env)
-		if isError(left) {
+		if interrupts(left) {
 			return left
 		}
+		if node.Operator == "&&" || node.Operator == "||" {
+			return evalLogicalExpression(node, left, env)
+		}
 		right := Eval(node.Right, env)
-		if isError(right) {
+		if interrupts(right) {
 			return right
 		}
-		return evalInfixExpression(node.Operator, left, right)
+		return evalInfixExpression(node.Operator, left, right, env)
 	case *ast.BlockStatement:
 		return evalBlockStatement(node, env)
 	case *ast.IfExpression:
 		return evalIfExpression(node, env)
 	case *ast.ReturnStatement:
 		val := Eval(node.ReturnValue, env)
-		if isError(val) {
+		if interrupts(val) {
 			return val
 		}
 		return &object.ReturnValue{Value: val}
+	case *ast.WhileStatement:
+		return evalWhileStatement(node, env)
+	case *ast.ForStatement:
+		return evalForStatement(node, env)
+	case *ast.BreakStatement:
+		return BREAK
+	case *ast.ContinueStatement:
+		return CONTINUE
 	case *ast.LetStatement:
 		val := Eval(node.Value, env)
-		if isError(val) {
+		if interrupts(val) {
 			return val
 		}
-		env.Set(node.Name.Value, val)
+		if !env.Declare(node.Name.Value, val, node.IsConst()) {
+			return newError("cannot redeclare constant %s", node.Name.Value)
+		}
 	case *ast.Identifier:
 		return evalIdentifier(node, env)
 	case *ast.FunctionLiteral:
@@ -65,17 +91,41 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		return &object.Function{Parameters: params, Env: env, Body: body}
 	case *ast.CallExpression:
 		function := Eval(node.Function, env)
-		if isError(function) {
+		if interrupts(function) {
 			return function
 		}
 		args := evalExpressions(node.Arguments, env)
-		if len(args) == 1 && isError(args[0]) {
+		if len(args) == 1 && interrupts(args[0]) {
 			return args[0]
 		}
 
-		return applyFunction(function, args)
+		return applyFunction(function, args, env)
 	case *ast.StringLiteral:
 		return &object.String{Value: node.Value}
+	case *ast.ArrayLiteral:
+		elements := evalExpressions(node.Elements, env)
+		if len(elements) == 1 && interrupts(elements[0]) {
+			return elements[0]
+		}
+		return &object.Array{Elements: elements}
+	case *ast.IndexExpression:
+		left := Eval(node.Left, env)
+		if interrupts(left) {
+			return left
+		}
+		index := Eval(node.Index, env)
+		if interrupts(index) {
+			return index
+		}
+		return evalIndexExpression(left, index)
+	case *ast.HashLiteral:
+		return evalHashLiteral(node, env)
+	case *ast.AssignExpression:
+		return evalAssignExpression(node, env)
+
+	// Placeholders produced by the parser for code with syntax errors
+	case *ast.BadStatement, *ast.BadExpression:
+		return newError("syntax error at %s", node.Pos())
 	}
 
 	return nil
@@ -88,12 +138,12 @@ func nativeBoolToBooleanObject(input bool) *object.Boolean {
 	return FALSE
 }
 
-func evalPrefixExpression(operator string, right object.Object) object.Object {
+func evalPrefixExpression(operator string, right object.Object, env *object.Environment) object.Object {
 	switch operator {
 	case "!":
 		return evalBangOperatorExpression(right)
 	case "-":
-		return evalMinusPrefixOperatorExpression(right)
+		return evalMinusPrefixOperatorExpression(right, env)
 	default:
 		return newError("unknown operator: %s%s", operator, right.Type())
 	}
@@ -112,19 +162,32 @@ func evalBangOperatorExpression(right object.Object) object.Object {
 	}
 }
 
-func evalMinusPrefixOperatorExpression(right object.Object) object.Object {
-	if right.Type() != object.INTEGER_OBJ {
+func evalMinusPrefixOperatorExpression(right object.Object, env *object.Environment) object.Object {
+	switch right := right.(type) {
+	case *object.Integer:
+		if right.Value == math.MinInt64 {
+			return integerOverflow(env, new(big.Int).Neg(toBigInt(right)), "-(%d)", right.Value)
+		}
+		return &object.Integer{Value: -right.Value}
+	case *object.BigInt:
+		return newInteger(new(big.Int).Neg(right.Value))
+	case *object.Float:
+		return &object.Float{Value: -right.Value}
+	default:
 		return newError("unknown operator: -%s", right.Type())
 	}
-
-	value := right.(*object.Integer).Value
-	return &object.Integer{Value: -value}
 }
 
-func evalInfixExpression(operator string, left, right object.Object) object.Object {
+func evalInfixExpression(operator string, left, right object.Object, env *object.Environment) object.Object {
 	switch {
 	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
-		return evalIntegerInfixExpression(operator, left, right)
+		return evalIntegerInfixExpression(operator, left, right, env)
+	case isInteger(left) && isInteger(right):
+		// at least one of the operands is a big integer
+		return evalBigIntInfixExpression(operator, left, right)
+	case isNumber(left) && isNumber(right):
+		// an integer operand is promoted when the other one is a float
+		return evalFloatInfixExpression(operator, left, right)
 	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
 		return evalStringInfixExpression(operator, left, right)
 	case operator == "==":
@@ -138,23 +201,40 @@ func evalInfixExpression(operator string, left, right object.Object) object.Obje
 	}
 }
 
-func evalIntegerInfixExpression(operator string, left, right object.Object) object.Object {
-	leftValue := left.(*object.Integer).Value
-	rightValue := right.(*object.Integer).Value
+// evalLogicalExpression evaluates the right operand of && and || only if the left one does not decide
+// the result, the result is the deciding operand
+func evalLogicalExpression(node *ast.InfixExpression, left object.Object, env *object.Environment) object.Object {
+	if isTruthy(left) == (node.Operator == "||") {
+		return left
+	}
+	return Eval(node.Right, env)
+}
+
+func evalFloatInfixExpression(operator string, left, right object.Object) object.Object {
+	leftValue := toFloat(left)
+	rightValue := toFloat(right)
 
 	switch operator {
 	case "+":
-		return &object.Integer{Value: leftValue + rightValue}
+		return &object.Float{Value: leftValue + rightValue}
 	case "-":
-		return &object.Integer{Value: leftValue - rightValue}
+		return &object.Float{Value: leftValue - rightValue}
 	case "*":
-		return &object.Integer{Value: leftValue * rightValue}
+		return &object.Float{Value: leftValue * rightValue}
 	case "/":
-		return &object.Integer{Value: leftValue / rightValue}
+		return &object.Float{Value: leftValue / rightValue}
+	case "%":
+		return &object.Float{Value: math.Mod(leftValue, rightValue)}
+	case "**":
+		return &object.Float{Value: math.Pow(leftValue, rightValue)}
 	case "<":
 		return nativeBoolToBooleanObject(leftValue < rightValue)
 	case ">":
 		return nativeBoolToBooleanObject(leftValue > rightValue)
+	case "<=":
+		return nativeBoolToBooleanObject(leftValue <= rightValue)
+	case ">=":
+		return nativeBoolToBooleanObject(leftValue >= rightValue)
 	case "==":
 		return nativeBoolToBooleanObject(leftValue == rightValue)
 	case "!=":
@@ -164,6 +244,23 @@ func evalIntegerInfixExpression(operator string, left, right object.Object) obje
 	}
 }
 
+func isNumber(obj object.Object) bool {
+	return isInteger(obj) || obj.Type() == object.FLOAT_OBJ
+}
+
+// toFloat returns the value of an integer or float object as a float
+func toFloat(obj object.Object) float64 {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return float64(obj.Value)
+	case *object.BigInt:
+		value, _ := new(big.Float).SetInt(obj.Value).Float64()
+		return value
+	default:
+		return obj.(*object.Float).Value
+	}
+}
+
 func evalStringInfixExpression(operator string, left, right object.Object) object.Object {
 	if operator != "+" {
 		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
@@ -177,7 +274,7 @@ func evalStringInfixExpression(operator string, left, right object.Object) objec
 func evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Object {
 	condition := Eval(ie.Condition, env)
 
-	if isError(condition) {
+	if interrupts(condition) {
 		return condition
 	}
 
@@ -190,6 +287,27 @@ func evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Obje
 	}
 }
 
+// evalWhileStatement evaluates the body in a new scope for every iteration, so declarations do not leak
+// out of the loop or clash with those of the previous iteration
+func evalWhileStatement(ws *ast.WhileStatement, env *object.Environment) object.Object {
+	for {
+		condition := Eval(ws.Condition, env)
+		if interrupts(condition) {
+			return condition
+		}
+		if !isTruthy(condition) {
+			return nil
+		}
+
+		switch result := Eval(ws.Body, object.NewEnclosedEnvironment(env)).(type) {
+		case *object.Break:
+			return nil
+		case *object.ReturnValue, *object.Error:
+			return result
+		}
+	}
+}
+
 func isTruthy(obj object.Object) bool {
 	switch obj {
 	case NULL:
@@ -203,15 +321,15 @@ func isTruthy(obj object.Object) bool {
 	}
 }
 
-func evalProgram(program *ast.Program, env *object.Environment) object.Object {
-	var result object.Object
+func evalProgram(program *ast.Program, env *object.Environment) (result object.Object) {
+	defer recoverInternalError(&result)
 
 	for _, statement := range program.Statements {
 		result = Eval(statement, env)
 
 		switch result := result.(type) {
-		case *object.ReturnValue:
-			return result.Value
+		case *object.ReturnValue, *object.Break, *object.Continue:
+			return unwrapReturnValue(result)
 		case *object.Error:
 			return result
 		}
@@ -229,30 +347,112 @@ func evalBlockStatement(block *ast.BlockStatement, env *object.Environment) obje
 		if result != nil {
 			rt := result.Type()
 
-			if rt == object.RETURN_VALUE_OBJ || rt == object.ERROR_OBJ {
+			if rt == object.RETURN_VALUE_OBJ || rt == object.ERROR_OBJ || rt == object.BREAK_OBJ || rt == object.CONTINUE_OBJ {
 				return result
 			}
 		}
 	}
 
+	if result == nil {
+		// the block is empty or ends in a statement without a value, like a let statement or a loop
+		return NULL
+	}
 	return result
 }
 
 func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object {
-	val, ok := env.Get(node.Value)
+	if val, ok := env.Get(node.Value); ok {
+		return val
+	}
+
+	if builtin, ok := builtins[node.Value]; ok {
+		return builtin
+	}
+
+	return newError("identifier not found: " + node.Value)
+}
+
+func evalIndexExpression(left, index object.Object) object.Object {
+	switch {
+	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
+		return evalArrayIndexExpression(left, index)
+	case left.Type() == object.ARRAY_OBJ:
+		return newError("array index must be INTEGER, got %s", index.Type())
+	case left.Type() == object.HASH_OBJ:
+		return evalHashIndexExpression(left, index)
+	default:
+		return newError("index operator not supported: %s", left.Type())
+	}
+}
+
+// evalArrayIndexExpression returns the element at the given index, negative indices count from the end of
+// the array and indices out of range evaluate to null
+func evalArrayIndexExpression(array, index object.Object) object.Object {
+	elements := array.(*object.Array).Elements
+	idx := index.(*object.Integer).Value
+	length := int64(len(elements))
+
+	if idx < 0 {
+		idx += length
+	}
+
+	if idx < 0 || idx >= length {
+		return NULL
+	}
+
+	return elements[idx]
+}
+
+func evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Object {
+	hash := object.NewHash()
+
+	for _, pair := range node.Pairs {
+		key := Eval(pair.Key, env)
+		if interrupts(key) {
+			return key
+		}
+
+		hashKey, ok := key.(object.Hashable)
+		if !ok {
+			return newError("unusable as hash key: %s", key.Type())
+		}
+
+		value := Eval(pair.Value, env)
+		if interrupts(value) {
+			return value
+		}
+
+		hash.Set(hashKey.HashKey(), object.HashPair{Key: key, Value: value})
+	}
+
+	return hash
+}
+
+func evalHashIndexExpression(hash, index object.Object) object.Object {
+	key, ok := index.(object.Hashable)
 	if !ok {
-		return newError("identifier not found: " + node.Value)
+		return newError("unusable as hash key: %s", index.Type())
 	}
-	return val
+
+	pair, ok := hash.(*object.Hash).Get(key.HashKey())
+	if !ok {
+		return NULL
+	}
+
+	return pair.Value
 }
 
 func newError(format string, a ...interface{}) *object.Error {
 	return &object.Error{Message: fmt.Sprintf(format, a...)}
 }
 
-func isError(obj object.Object) bool {
-	if obj != nil {
-		return obj.Type() == object.ERROR_OBJ
+// interrupts reports whether obj ends the evaluation of the enclosing expressions and statements, which
+// is the case for errors and for the results of return, break and continue statements that come out of a
+// block used as an expression
+func interrupts(obj object.Object) bool {
+	switch obj.(type) {
+	case *object.Error, *object.ReturnValue, *object.Break, *object.Continue:
+		return true
 	}
 	return false
 }
@@ -262,7 +462,7 @@ func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Ob
 
 	for _, e := range exps {
 		evaluated := Eval(e, env)
-		if isError(evaluated) {
+		if interrupts(evaluated) {
 			return []object.Object{evaluated}
 		}
 		result = append(result, evaluated)
@@ -271,15 +471,51 @@ func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Ob
 	return result
 }
 
-func applyFunction(fn object.Object, args []object.Object) object.Object {
-	function, ok := fn.(*object.Function)
-	if !ok {
-		return newError("not a function: %s", fn.Type())
+// ApplyFunction calls a Monkey function or builtin with the given arguments, builtins write to the output
+// of the environment
+func ApplyFunction(fn object.Object, args []object.Object, env *object.Environment) (result object.Object) {
+	defer recoverInternalError(&result)
+	return applyFunction(fn, args, env)
+}
+
+// recoverInternalError turns a panic of the evaluator into an error object stored in result, it must
+// be deferred by the functions through which evaluation is entered so a bug does not crash the host
+func recoverInternalError(result *object.Object) {
+	if r := recover(); r != nil {
+		*result = newError("internal error: %v", r)
 	}
+}
 
-	extendedEnv := extendFunctionEnv(function, args)
-	evaluated := Eval(function.Body, extendedEnv)
-	return unwrapReturnValue(evaluated)
+// applyFunction calls the function, env is the environment of the caller
+func applyFunction(fn object.Object, args []object.Object, env *object.Environment) object.Object {
+	switch function := fn.(type) {
+	case *object.Function:
+		if len(args) != len(function.Parameters) {
+			return wrongNumberOfArguments(len(args), len(function.Parameters))
+		}
+		// unbounded recursion would exhaust the Go stack, which cannot be recovered from
+		if !function.Env.EnterCall(maxCallDepth) {
+			return newError("maximum call depth of %d exceeded", maxCallDepth)
+		}
+		defer function.Env.LeaveCall()
+
+		extendedEnv := extendFunctionEnv(function, args)
+		evaluated := Eval(function.Body, extendedEnv)
+		return unwrapReturnValue(evaluated)
+	case *object.Builtin:
+		var result object.Object
+		if function.EnvFn != nil {
+			result = function.EnvFn(env, args...)
+		} else {
+			result = function.Fn(args...)
+		}
+		if result != nil {
+			return result
+		}
+		return NULL
+	default:
+		return newError("not a function: %s", fn.Type())
+	}
 }
 
 func extendFunctionEnv(fn *object.Function, args []object.Object) *object.Environment {
@@ -290,9 +526,16 @@ func extendFunctionEnv(fn *object.Function, args []object.Object) *object.Enviro
 	return env
 }
 
+// unwrapReturnValue returns the value of a return statement that ended a function body or program, a
+// break or continue that reached it was not inside a loop
 func unwrapReturnValue(obj object.Object) object.Object {
-	if returnValue, ok := obj.(*object.ReturnValue); ok {
-		return returnValue.Value
+	switch obj := obj.(type) {
+	case *object.ReturnValue:
+		return obj.Value
+	case *object.Break:
+		return newError("break outside loop")
+	case *object.Continue:
+		return newError("continue outside loop")
 	}
 	return obj
 }
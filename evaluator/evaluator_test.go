@@ -1,10 +1,15 @@
 package evaluator_test
 
 import (
+	"bytes"
+	"io"
+	"math"
+	"monkey/ast"
 	"monkey/evaluator"
 	"monkey/lexer"
 	"monkey/object"
 	"monkey/parser"
+	"monkey/token"
 	"testing"
 
 	"github.com/stretchr/testify/suite"
@@ -40,6 +45,19 @@ func (s *Suite) TestEvalIntegerExpression() {
 		{"3 * 3 * 3 + 10", 37},
 		{"3 * (3 * 3) + 10", 37},
 		{"(5 + 10 * 2 + 15 / 3) * 2 + -10", 50},
+		{"0xff + 0o7 + 0b1", 263},
+		{"7 % 3", 1},
+		{"-7 % 3", -1},
+		{"2 ** 10", 1024},
+		{"2 ** 3 ** 2", 512},
+		{"-2 ** 2", -4},
+		{"6 & 3", 2},
+		{"6 | 3", 7},
+		{"6 ^ 3", 5},
+		{"1 << 10", 1024},
+		{"-16 >> 2", -4},
+		{"1 >> 64", 0},
+		{"1_000 * 1_000", 1000000},
 	}
 
 	for _, tt := range tests {
@@ -48,6 +66,142 @@ func (s *Suite) TestEvalIntegerExpression() {
 	}
 }
 
+func (s *Suite) TestEvalFloatExpression() {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"3.14", 3.14},
+		{".5", 0.5},
+		{"1e3", 1000},
+		{"-2.5", -2.5},
+		{"1.5 + 1.5", 3},
+		{"1 + 0.5", 1.5},
+		{"0.5 * 4", 2},
+		{"7 / 2.0", 3.5},
+		{"10 - 2.5 * 2", 5},
+		{"1 / 4 * 1.0", 0},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testFloatObject(s, evaluated, tt.expected)
+	}
+}
+
+func (s *Suite) TestIntegerOverflowPromotes() {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"9223372036854775807 + 1", "9223372036854775808"},
+		{"-9223372036854775807 - 2", "-9223372036854775809"},
+		{"4294967296 * 4294967296", "18446744073709551616"},
+		{"-(-9223372036854775807 - 1)", "9223372036854775808"},
+		{"(-9223372036854775807 - 1) / -1", "9223372036854775808"},
+		{"99999999999999999999", "99999999999999999999"},
+		{"-99999999999999999999", "-99999999999999999999"},
+		{"99999999999999999999 * 3", "299999999999999999997"},
+		{"99999999999999999999 < 100000000000000000000", "true"},
+		{"99999999999999999999 == 99999999999999999999", "true"},
+		{"99999999999999999999 > 1.5", "true"},
+		{"99999999999999999999 / 2.0", "5e+19"},
+		{`type(99999999999999999999)`, "BIGINT"},
+		{`{99999999999999999999: "big"}[99999999999999999999]`, "big"},
+	}
+
+	for _, tt := range tests {
+		s.Require().Equal(tt.expected, testEval(tt.input).Inspect(), tt.input)
+	}
+
+	// results that fit in an int64 again are plain integers
+	testIntegerObject(s, testEval("9223372036854775807 + 1 - 2"), 9223372036854775806)
+	testIntegerObject(s, testEval("int(99999999999999999999 / 99999999999999999999)"), 1)
+}
+
+func (s *Suite) TestLogicalOperators() {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		// the deciding operand is the result
+		{`1 && "yes"`, "yes"},
+		{`0 || "fallback"`, "0"},
+		{`if (false) { 1 } || "fallback"`, "fallback"},
+		{`false && x`, "false"},
+		{`true || x`, "true"},
+		{`true && x`, "ERROR: identifier not found: x"},
+		{`let calls = [0]; let f = fn() { push(calls, 1) }; false && f(); true || f(); len(calls)`, "1"},
+	}
+
+	for _, tt := range tests {
+		s.Require().Equal(tt.expected, testEval(tt.input).Inspect(), tt.input)
+	}
+}
+
+func (s *Suite) TestArithmeticOperators() {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"2 ** 64", "18446744073709551616"},
+		{"1 << 64", "18446744073709551616"},
+		{"2 ** -1", "0.5"},
+		{"2.0 ** 0.5 > 1.41", "true"},
+		{"7.5 % 2", "1.5"},
+		{"99999999999999999999 % 10", "9"},
+		{"99999999999999999999 >> 60", "86"},
+		{"(1 << 70) | 1", "1180591620717411303425"},
+		{"5 % 0", "ERROR: division by zero"},
+		{"1 << -1", "ERROR: negative shift count: -1"},
+		{"1 >> -1", "ERROR: negative shift count: -1"},
+		{"2 ** 99999999999", "ERROR: exponent too large: 99999999999"},
+		{"1 ** 99999999999", "1"},
+		{"1.5 & 1", "ERROR: unknown operator: FLOAT & INTEGER"},
+		{`"a" <= "b"`, "ERROR: unknown operator: STRING <= STRING"},
+	}
+
+	for _, tt := range tests {
+		s.Require().Equal(tt.expected, testEval(tt.input).Inspect(), tt.input)
+	}
+}
+
+func (s *Suite) TestIntegerOverflowErrors() {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"9223372036854775807 + 1", "integer overflow: 9223372036854775807 + 1"},
+		{"-9223372036854775807 - 2", "integer overflow: -9223372036854775807 - 2"},
+		{"let f = fn(x) { x * x }; f(4294967296)", "integer overflow: 4294967296 * 4294967296"},
+		{"-(-9223372036854775807 - 1)", "integer overflow: -(-9223372036854775808)"},
+		{"99999999999999999999", "integer overflow: 99999999999999999999 does not fit in INTEGER"},
+		{"2 ** 64", "integer overflow: 2 ** 64"},
+		{"1 << 63", "integer overflow: 1 << 63"},
+		{"-9223372036854775809", "integer overflow: -9223372036854775809 does not fit in INTEGER"},
+		{"-9223372036854775808 - 1", "integer overflow: -9223372036854775808 - 1"},
+	}
+
+	for _, tt := range tests {
+		env := object.NewEnvironment()
+		env.SetIntegerOverflow(object.ErrorOnOverflow)
+
+		evaluated := evaluator.Eval(parser.New(lexer.New(tt.input)).ParseProgram(), env)
+		errObj, ok := evaluated.(*object.Error)
+		s.Require().Truef(ok, "no error object returned for %s. got=%T(%+v)", tt.input, evaluated, evaluated)
+		s.Require().Equal(tt.expected, errObj.Message)
+	}
+
+	// the smallest INTEGER can be written as a literal although its magnitude does not fit
+	for _, input := range []string{"-9223372036854775808", "-0x8000000000000000", "-9223372036854775807 - 1"} {
+		env := object.NewEnvironment()
+		env.SetIntegerOverflow(object.ErrorOnOverflow)
+
+		evaluated := evaluator.Eval(parser.New(lexer.New(input)).ParseProgram(), env)
+		testIntegerObject(s, evaluated, math.MinInt64)
+	}
+}
+
 func (s *Suite) TestEvalBooleanExpression() {
 	tests := []struct {
 		input    string
@@ -59,6 +213,21 @@ func (s *Suite) TestEvalBooleanExpression() {
 		{"(1 < 2) == false", false},
 		{"(1 > 2) == true", false},
 		{"(1 > 2) == false", true},
+		{"1.5 < 2", true},
+		{"2 > 1.5", true},
+		{"1 == 1.0", true},
+		{"0.1 + 0.2 != 0.3", true},
+		{"1 <= 1", true},
+		{"2 <= 1", false},
+		{"1 >= 1", true},
+		{"1 >= 2", false},
+		{"1.5 <= 2", true},
+		{"99999999999999999999 >= 99999999999999999999", true},
+		{"true && true", true},
+		{"true && false", false},
+		{"false || true", true},
+		{"false || false", false},
+		{"1 < 2 && 2 < 3", true},
 	}
 
 	for _, tt := range tests {
@@ -207,6 +376,42 @@ func (s *Suite) TestErrorHandling() {
 			`"Hello" - "World"`,
 			"unknown operator: STRING - STRING",
 		},
+		{
+			`[1, 2]["a"]`,
+			"array index must be INTEGER, got STRING",
+		},
+		{
+			`5[0]`,
+			"index operator not supported: INTEGER",
+		},
+		{
+			`{"name": "Monkey"}[fn(x) { x }];`,
+			"unusable as hash key: FUNCTION",
+		},
+		{
+			`{fn(x) { x }: "Monkey"};`,
+			"unusable as hash key: FUNCTION",
+		},
+		{
+			"5 / 0",
+			"division by zero",
+		},
+		{
+			"let zero = fn() { 0 }; 1 + 10 / zero()",
+			"division by zero",
+		},
+		{
+			"99999999999999999999 / 0",
+			"division by zero",
+		},
+		{
+			"fn(a, b) { a }(1)",
+			"wrong number of arguments: want=2, got=1",
+		},
+		{
+			"let f = fn() { 1 }; f(1, 2, 3)",
+			"wrong number of arguments: want=0, got=3",
+		},
 	}
 	for _, tt := range tests {
 		evaluated := testEval(tt.input)
@@ -287,6 +492,16 @@ func (s *Suite) TestStringLiteral() {
 	s.Require().Equal("Hello World!", str.Value)
 }
 
+func (s *Suite) TestStringEscapes() {
+	input := `"say \"hi\"\n" + ` + "`raw\\n`"
+
+	evaluated := testEval(input)
+	str, ok := evaluated.(*object.String)
+	s.Require().Truef(ok, "expected *object.String but got %T", evaluated)
+
+	s.Require().Equal("say \"hi\"\nraw\\n", str.Value)
+}
+
 func (s *Suite) TestStringConcatenation() {
 	input := `"Hello" + " " +  "World!"`
 
@@ -302,6 +517,7 @@ func testEval(input string) object.Object {
 	p := parser.New(lex)
 	program := p.ParseProgram()
 	env := object.NewEnvironment()
+	env.SetOutput(io.Discard)
 
 	return evaluator.Eval(program, env)
 }
@@ -313,6 +529,13 @@ func testIntegerObject(s *Suite, obj object.Object, expected int64) {
 	s.Require().Equal(expected, result.Value)
 }
 
+func testFloatObject(s *Suite, obj object.Object, expected float64) {
+	result, ok := obj.(*object.Float)
+	s.Require().True(ok, "expected *object.Float but got %T", obj)
+
+	s.Require().Equal(expected, result.Value)
+}
+
 func testBooleanObject(s *Suite, obj object.Object, expected bool) {
 	result, ok := obj.(*object.Boolean)
 	s.Require().Truef(ok, "expected *object.Boolean but got %T", obj)
@@ -320,6 +543,464 @@ func testBooleanObject(s *Suite, obj object.Object, expected bool) {
 	s.Require().Equal(expected, result.Value)
 }
 
+var (
+	TRUE  = evaluator.TRUE
+	FALSE = evaluator.FALSE
+)
+
 func testNullObject(s *Suite, obj object.Object) {
 	s.Require().Equal(evaluator.NULL, obj)
 }
+
+func (s *Suite) TestArrayLiterals() {
+	input := "[1, 2 * 2, 3 + 3]"
+
+	evaluated := testEval(input)
+	result, ok := evaluated.(*object.Array)
+	s.Require().Truef(ok, "expected *object.Array but got %T (%+v)", evaluated, evaluated)
+
+	s.Require().Len(result.Elements, 3)
+
+	testIntegerObject(s, result.Elements[0], 1)
+	testIntegerObject(s, result.Elements[1], 4)
+	testIntegerObject(s, result.Elements[2], 6)
+}
+
+func (s *Suite) TestEmptyBodiesAreNull() {
+	tests := []string{
+		"[fn(){}()]",
+		"[if (true) {}]",
+		"let f = fn() { let x = 1; }; [f()]",
+		"[fn() { while (false) {} }()]",
+	}
+
+	for _, input := range tests {
+		s.Require().Equal("[null]", testEval(input).Inspect(), input)
+	}
+}
+
+func (s *Suite) TestArrayIndexExpressions() {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"[1, 2, 3][0]", 1},
+		{"[1, 2, 3][1]", 2},
+		{"[1, 2, 3][2]", 3},
+		{"let i = 0; [1][i];", 1},
+		{"[1, 2, 3][1 + 1];", 3},
+		{"let myArray = [1, 2, 3]; myArray[2];", 3},
+		{"let myArray = [1, 2, 3]; myArray[0] + myArray[1] + myArray[2];", 6},
+		{"let myArray = [1, 2, 3]; let i = myArray[0]; myArray[i]", 2},
+		{"[1, 2, 3][3]", nil},
+		{"[1, 2, 3][-1]", 3},
+		{"[1, 2, 3][-3]", 1},
+		{"[1, 2, 3][-4]", nil},
+		{"[][0]", nil},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		integer, ok := tt.expected.(int)
+		if ok {
+			testIntegerObject(s, evaluated, int64(integer))
+		} else {
+			testNullObject(s, evaluated)
+		}
+	}
+}
+
+func (s *Suite) TestHashLiterals() {
+	input := `let two = "two";
+	{
+		"one": 10 - 9,
+		two: 1 + 1,
+		"thr" + "ee": 6 / 2,
+		4: 4,
+		true: 5,
+		false: 6
+	}`
+
+	evaluated := testEval(input)
+	result, ok := evaluated.(*object.Hash)
+	s.Require().Truef(ok, "expected *object.Hash but got %T (%+v)", evaluated, evaluated)
+
+	expected := map[object.HashKey]int64{
+		(&object.String{Value: "one"}).HashKey():   1,
+		(&object.String{Value: "two"}).HashKey():   2,
+		(&object.String{Value: "three"}).HashKey(): 3,
+		(&object.Integer{Value: 4}).HashKey():      4,
+		TRUE.HashKey():                             5,
+		FALSE.HashKey():                            6,
+	}
+
+	s.Require().Equal(len(expected), result.Len())
+
+	for expectedKey, expectedValue := range expected {
+		pair, ok := result.Get(expectedKey)
+		s.Require().True(ok, "no pair for given key in Pairs")
+
+		testIntegerObject(s, pair.Value, expectedValue)
+	}
+
+	s.Require().Equal(`{"one": 1, "two": 2, "three": 3, 4: 4, true: 5, false: 6}`, result.Inspect())
+}
+
+func (s *Suite) TestHashIndexExpressions() {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`{"foo": 5}["foo"]`, 5},
+		{`{"foo": 5}["bar"]`, nil},
+		{`let key = "foo"; {"foo": 5}[key]`, 5},
+		{`{}["foo"]`, nil},
+		{`{5: 5}[5]`, 5},
+		{`{true: 5}[true]`, 5},
+		{`{false: 5}[false]`, 5},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		integer, ok := tt.expected.(int)
+		if ok {
+			testIntegerObject(s, evaluated, int64(integer))
+		} else {
+			testNullObject(s, evaluated)
+		}
+	}
+}
+
+func (s *Suite) TestBuiltinFunctions() {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`len("")`, 0},
+		{`len("four")`, 4},
+		{`len("hello world")`, 11},
+		{`len(1)`, "argument to `len` not supported, got INTEGER"},
+		{`len("one", "two")`, "wrong number of arguments: want=1, got=2"},
+		{`len([1, 2, 3])`, 3},
+		{`len([])`, 0},
+		{`len({"a": 1, "b": 2})`, 2},
+		{`first([1, 2, 3])`, 1},
+		{`first([])`, nil},
+		{`first(1)`, "argument to `first` must be ARRAY, got INTEGER"},
+		{`last([1, 2, 3])`, 3},
+		{`last([])`, nil},
+		{`last(1)`, "argument to `last` must be ARRAY, got INTEGER"},
+		{`rest([1, 2, 3])`, []int{2, 3}},
+		{`rest([])`, nil},
+		{`push([], 1)`, []int{1}},
+		{`push(1, 1)`, "argument to `push` must be ARRAY, got INTEGER"},
+		{`let a = [1]; push(a, 2); a`, []int{1}},
+		{`puts("hello")`, nil},
+		{`puts(if (false) {1} else {})`, nil},
+		{`type(fn(){}())`, "NULL"},
+		{`len(if (true) {})`, "argument to `len` not supported, got NULL"},
+		{`str(fn(){}())`, "null"},
+		{`type(1)`, "INTEGER"},
+		{`type(len)`, "BUILTIN"},
+		{`str(12)`, "12"},
+		{`str([1, "a"])`, "[1, a]"},
+		{`int("42")`, 42},
+		{`int("0x10")`, 16},
+		{`int(true)`, 1},
+		{`int("abc")`, `could not convert "abc" to INTEGER`},
+		{`int([])`, "argument to `int` not supported, got ARRAY"},
+		{`int(2.9)`, 2},
+		{`int(-2.9)`, -2},
+		{`int(1e19)`, "could not convert 1e+19 to INTEGER"},
+		{`float(2)`, 2.0},
+		{`float(2.5)`, 2.5},
+		{`float("1e-3")`, 0.001},
+		{`float("abc")`, `could not convert "abc" to FLOAT`},
+		{`float(true)`, "argument to `float` not supported, got BOOLEAN"},
+		{`type(1.0)`, "FLOAT"},
+		{`str(1.0)`, "1.0"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(s, evaluated, int64(expected))
+		case float64:
+			testFloatObject(s, evaluated, expected)
+		case nil:
+			testNullObject(s, evaluated)
+		case string:
+			switch obj := evaluated.(type) {
+			case *object.Error:
+				s.Require().Equal(expected, obj.Message, tt.input)
+			case *object.String:
+				s.Require().Equal(expected, obj.Value, tt.input)
+			default:
+				s.Require().Failf("unexpected object", "%s: got=%T (%+v)", tt.input, evaluated, evaluated)
+			}
+		case []int:
+			array, ok := evaluated.(*object.Array)
+			s.Require().Truef(ok, "expected *object.Array but got %T (%+v)", evaluated, evaluated)
+
+			s.Require().Len(array.Elements, len(expected))
+			for i, expectedElem := range expected {
+				testIntegerObject(s, array.Elements[i], int64(expectedElem))
+			}
+		}
+	}
+}
+
+func (s *Suite) TestInternalErrors() {
+	env := object.NewEnvironment()
+	env.Set("crash", &object.Builtin{Name: "crash", Fn: func(args ...object.Object) object.Object {
+		panic("something broke")
+	}})
+
+	program := parser.New(lexer.New(`let x = 1; crash(); x`)).ParseProgram()
+	evaluated := evaluator.Eval(program, env)
+
+	errObj, ok := evaluated.(*object.Error)
+	s.Require().Truef(ok, "no error object returned. got=%T(%+v)", evaluated, evaluated)
+	s.Require().Equal("internal error: something broke", errObj.Message)
+
+	crash, _ := env.Get("crash")
+	evaluated = evaluator.ApplyFunction(crash, nil, env)
+	s.Require().Equal("ERROR: internal error: something broke", evaluated.Inspect())
+}
+
+func (s *Suite) TestCallDepthLimit() {
+	env := object.NewEnvironment()
+	eval := func(input string) object.Object {
+		return evaluator.Eval(parser.New(lexer.New(input)).ParseProgram(), env)
+	}
+
+	s.Require().Equal("ERROR: maximum call depth of 10000 exceeded", eval("let f = fn(x) { f(x) }; f(1)").Inspect())
+	s.Require().Equal("ERROR: maximum call depth of 10000 exceeded", eval("let g = fn(x) { [g(x)] }; g(1)").Inspect())
+
+	// the calls that failed are no longer counted
+	s.Require().Equal("9999", eval("let count = fn(n) { if (n == 0) { 0 } else { 1 + count(n - 1) } }; count(9999)").Inspect())
+}
+
+func (s *Suite) TestFloatDivisionByZero() {
+	s.Require().Equal("+Inf", testEval("1.0 / 0").Inspect())
+}
+
+func (s *Suite) TestAssignment() {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x = 1; x = 2; x", "2"},
+		{"let x = 1; x = x + 1", "2"},
+		{"let x = 1; let y = 2; x = y = 3; x + y", "6"},
+		{"let x = 10; x += 5; x -= 3; x *= 2; x /= 4; x", "6"},
+		{`let s = "a"; s += "b"; s`, "ab"},
+		{"let x = 1.5; x *= 2; x", "3.0"},
+		{`
+let newCounter = fn() {
+	let count = 0;
+	fn() { count += 1 }
+};
+let counter = newCounter();
+counter(); counter();
+counter()`, "3"},
+		{`
+let total = 0;
+let add = fn(x) { total = total + x; };
+add(2); add(3);
+total`, "5"},
+		{`
+let x = 1;
+let shadow = fn() { let x = 2; x = 3; x };
+shadow() + x`, "4"},
+		{"let a = [1, 2, 3]; a[0] = 10; a[-1] *= 2; a", "[10, 2, 6]"},
+		{`let h = {"a": 1}; h["a"] += 1; h["b"] = 5; h`, `{"a": 2, "b": 5}`},
+		{"let a = [1]; let b = a; b[0] = 2; a", "[2]"},
+		{"let a = [[1, 2], [3]]; a[0][1] = 5; a", "[[1, 5], [3]]"},
+		{"let a = [1, 2]; a[0] = a; str(a)", "[[...], 2]"},
+		{`let h = {"a": 1}; h["a"] = [h, h]; h`, `{"a": [{...}, {...}]}`},
+		{"let a = [1]; let b = [a, a]; b", "[[1], [1]]"},
+		{"y = 1", "ERROR: cannot assign to undefined variable y"},
+		{"y += 1", "ERROR: identifier not found: y"},
+		{"len = 1", "ERROR: cannot assign to undefined variable len"},
+		{"let x = 1; x += true", "ERROR: type mismatch: INTEGER + BOOLEAN"},
+		{"let a = [1]; a[1] = 2", "ERROR: index out of range: 1 with length 1"},
+		{`let a = [1]; a["x"] = 2`, "ERROR: array index must be INTEGER, got STRING"},
+		{`let h = {}; h["missing"] += 1`, "ERROR: key not found: missing"},
+		{`let h = {}; h[fn() {}] = 1`, "ERROR: unusable as hash key: FUNCTION"},
+		{`let s = "abc"; s[0] = "x"`, "ERROR: index assignment not supported: STRING"},
+		{"let x = 1; x /= 0", "ERROR: division by zero"},
+	}
+
+	for _, tt := range tests {
+		s.Require().Equal(tt.expected, testEval(tt.input).Inspect(), tt.input)
+	}
+}
+
+func (s *Suite) TestConstants() {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"const x = 1; x", "1"},
+		{"const x = 1; let f = fn() { let x = 2; x = 3; x }; f() + x", "4"},
+		{"const x = 1; let f = fn(x) { x += 1 }; f(x) + x", "3"},
+		{"let x = 1; const x = 2; x", "2"},
+		{"let a = true; if (a) { const x = 1 } else { const x = 2 }; x", "1"},
+		{"if (true) { const x = 1 }; let x = 2", "ERROR: cannot redeclare constant x"},
+	}
+
+	for _, tt := range tests {
+		s.Require().Equal(tt.expected, testEval(tt.input).Inspect(), tt.input)
+	}
+}
+
+func (s *Suite) TestConstantErrors() {
+	// the parser reports these within a single program, so each line is evaluated separately like in the repl
+	tests := []struct {
+		inputs   []string
+		expected string
+	}{
+		{[]string{"const x = 1;", "x = 2"}, "ERROR: cannot assign to constant x"},
+		{[]string{"const x = 1;", "x += 1"}, "ERROR: cannot assign to constant x"},
+		{[]string{"const x = 1;", "let x = 2"}, "ERROR: cannot redeclare constant x"},
+		{[]string{"const x = 1;", "const x = 2"}, "ERROR: cannot redeclare constant x"},
+		{[]string{"const x = 1;", "let f = fn() { x = 2 };", "f()"}, "ERROR: cannot assign to constant x"},
+		{[]string{"const x = 1;", "let y = 0;", "x = y = 5", "y"}, "0"},
+	}
+
+	for _, tt := range tests {
+		env := object.NewEnvironment()
+		var result object.Object
+		for _, input := range tt.inputs {
+			result = evaluator.Eval(parser.New(lexer.New(input)).ParseProgram(), env)
+		}
+		s.Require().Equal(tt.expected, result.Inspect(), tt.inputs)
+	}
+}
+
+func (s *Suite) TestWhileLoops() {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let i = 0; while (i < 5) { i += 1 }; i", "5"},
+		{"let i = 0; while (false) { i += 1 }; i", "0"},
+		{"let i = 0; while (true) { i += 1; if (i == 3) { break } }; i", "3"},
+		{"let i = 0; let sum = 0; while (i < 5) { i += 1; if (i % 2 == 0) { continue; } sum += i }; sum", "9"},
+		{"let i = 0; let n = 0; while (i < 3) { i += 1; let j = 0; while (true) { j += 1; n += 1; if (j == 2) { break } } }; n", "6"},
+		{"let f = fn() { let i = 0; while (true) { i += 1; if (i == 4) { return i * 10 } } }; f()", "40"},
+		{"let i = 0; while (i < 2) { const c = i; i += 1 }; i", "2"},
+		{"let i = 0; while (i < 1) { let inner = 1; i += 1 }; inner", "ERROR: identifier not found: inner"},
+		{"while (x) { }", "ERROR: identifier not found: x"},
+		{"let i = 0; while (i < 3) { i += true }", "ERROR: type mismatch: INTEGER + BOOLEAN"},
+		{"let i = 0; while (i < 100000) { i += 1 }; i", "100000"},
+	}
+
+	for _, tt := range tests {
+		s.Require().Equal(tt.expected, testEval(tt.input).Inspect(), tt.input)
+	}
+}
+
+func (s *Suite) TestForLoops() {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let sum = 0; for (x in [1, 2, 3]) { sum += x }; sum", "6"},
+		{"let out = []; for (i, x in [10, 20]) { out = push(out, [i, x]) }; out", "[[0, 10], [1, 20]]"},
+		{`let out = ""; for (k in {"b": 1, "a": 2, "c": 3}) { out += k }; out`, "bac"},
+		{`let out = []; for (k, v in {"b": 1, "a": 2}) { out = push(out, k + str(v)) }; out`, "[b1, a2]"},
+		{`let out = []; for (c in "héllo") { out = push(out, c) }; out`, "[h, é, l, l, o]"},
+		{`let out = []; for (i, c in "hé!") { out = push(out, str(i) + c) }; out`, "[0h, 1é, 2!]"},
+		{"let out = []; for (i in range(5)) { out = push(out, i) }; out", "[0, 1, 2, 3, 4]"},
+		{"let out = []; for (i in range(2, 5)) { out = push(out, i) }; out", "[2, 3, 4]"},
+		{"let out = []; for (i in range(10, 0, -3)) { out = push(out, i) }; out", "[10, 7, 4, 1]"},
+		{"let out = []; for (i, x in range(5, 11, 5)) { out = push(out, [i, x]) }; out", "[[0, 5], [1, 10]]"},
+		{"let n = 0; for (i in range(5, 0)) { n += 1 }; n", "0"},
+		{"let sum = 0; for (i in range(10)) { if (i == 5) { break } if (i % 2 == 0) { continue } sum += i }; sum", "4"},
+		{"let n = 0; for (row in [[1, 2], [3]]) { for (x in row) { n += x } }; n", "6"},
+		{"let find = fn(xs, y) { for (i, x in xs) { if (x == y) { return i } } -1 }; [find([5, 6], 6), find([], 1)]", "[1, -1]"},
+		{"let fns = []; for (i in range(3)) { fns = push(fns, fn() { i }) }; [fns[0](), fns[1](), fns[2]()]", "[0, 1, 2]"},
+		{"let x = 10; for (x in [1, 2]) { x += 5 }; x", "10"},
+		{"let a = [1, 2]; for (x in a) { a = push(a, x) }; a", "[1, 2, 1, 2]"},
+		{"for (x in [1]) { let inner = x }; inner", "ERROR: identifier not found: inner"},
+		{"for (x in 5) { }", "ERROR: cannot iterate over INTEGER"},
+		{"for (x in fn() {}) { }", "ERROR: cannot iterate over FUNCTION"},
+		{"for (x in [1, true]) { x + 1 }", "ERROR: type mismatch: BOOLEAN + INTEGER"},
+		{"for (x in y) { }", "ERROR: identifier not found: y"},
+	}
+
+	for _, tt := range tests {
+		s.Require().Equal(tt.expected, testEval(tt.input).Inspect(), tt.input)
+	}
+}
+
+func (s *Suite) TestRangeBuiltin() {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"range(3)", "range(0, 3, 1)"},
+		{"range(1, 3)", "range(1, 3, 1)"},
+		{"range(3, 1, -1)", "range(3, 1, -1)"},
+		{"len(range(0, 10, 3))", "4"},
+		{"len(range(0, 10, -1))", "0"},
+		{"len(range(-9223372036854775807 - 1, 9223372036854775807))", "18446744073709551615"},
+		{"range()", "ERROR: wrong number of arguments: want=1 to 3, got=0"},
+		{"range(1, 2, 3, 4)", "ERROR: wrong number of arguments: want=1 to 3, got=4"},
+		{"range(1.5)", "ERROR: arguments to `range` must be INTEGER, got FLOAT"},
+		{"range(0, 10, 0)", "ERROR: range step must not be zero"},
+	}
+
+	for _, tt := range tests {
+		s.Require().Equal(tt.expected, testEval(tt.input).Inspect(), tt.input)
+	}
+}
+
+func (s *Suite) TestLoopControlInExpressions() {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let i = 0; while (i < 5) { i += 1; let x = if (i > 2) { break; }; }; i", "3"},
+		{"let i = 0; let n = 0; while (i < 5) { i += 1; let x = if (i % 2 == 0) { continue } else { 1 }; n += x }; n", "3"},
+		{"let i = 0; while (i < 5) { i += 1; str(if (i > 2) { break; }) }; i", "3"},
+		{"let i = 0; while (i < 5) { i += 1; [1, if (i > 1) { break; }] }; i", "2"},
+		{`let i = 0; while (i < 5) { i += 1; {"k": if (i > 3) { break; }} }; i`, "4"},
+		{`let i = 0; while (i < 5) { i += 1; {if (i > 3) { break; } else { "k" }: 1} }; i`, "4"},
+		{"let i = 0; while (i < 5) { i += 1; let y = 1 + if (i > 1) { break } else { 0 } }; i", "2"},
+		{"let f = fn() { let x = if (true) { return 7 }; 0 }; f()", "7"},
+		{"let f = fn(x) { x }; f(if (true) { return 8 }); 0", "8"},
+	}
+
+	for _, tt := range tests {
+		s.Require().Equal(tt.expected, testEval(tt.input).Inspect(), tt.input)
+	}
+
+	var out bytes.Buffer
+	env := object.NewEnvironment()
+	env.SetOutput(&out)
+
+	evaluator.Eval(parser.New(lexer.New("let i = 0; while (i < 5) { i += 1; puts(if (i > 2) { break; }) }")).ParseProgram(), env)
+	s.Require().Equal("null\nnull\n", out.String())
+}
+
+func (s *Suite) TestLoopControlOutsideLoop() {
+	// the parser rejects these, so build the programs by hand
+	breakStmt := &ast.BreakStatement{Token: token.Token{Type: token.BREAK, Literal: "break"}}
+	continueStmt := &ast.ContinueStatement{Token: token.Token{Type: token.CONTINUE, Literal: "continue"}}
+
+	env := object.NewEnvironment()
+	result := evaluator.Eval(&ast.Program{Statements: []ast.Statement{breakStmt}}, env)
+	s.Require().Equal("ERROR: break outside loop", result.Inspect())
+
+	fn := &object.Function{Body: &ast.BlockStatement{Statements: []ast.Statement{continueStmt}}, Env: env}
+	s.Require().Equal("ERROR: continue outside loop", evaluator.ApplyFunction(fn, nil, env).Inspect())
+}
+
+func (s *Suite) TestBuiltinShadowing() {
+	testIntegerObject(s, testEval(`let len = fn(x) { 42 }; len("abc")`), 42)
+}
@@ -0,0 +1,200 @@
+package evaluator
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	"monkey/ast"
+	"monkey/object"
+)
+
+// limits for shift counts and exponents so a typo cannot allocate gigabytes for a big integer
+const (
+	maxShift    = 1 << 20
+	maxExponent = 1 << 20
+)
+
+func evalIntegerLiteral(node *ast.IntegerLiteral, env *object.Environment) object.Object {
+	if node.Big == nil {
+		return &object.Integer{Value: node.Value}
+	}
+	if env.IntegerOverflow() == object.ErrorOnOverflow {
+		return newError("integer overflow: %s does not fit in INTEGER", node.Token.Literal)
+	}
+	return &object.BigInt{Value: node.Big}
+}
+
+// evalNegatedIntegerLiteral negates a literal that does not fit in an int64 before the overflow check, so
+// -9223372036854775808 is an INTEGER even when big integers are an error
+func evalNegatedIntegerLiteral(node *ast.IntegerLiteral, env *object.Environment) object.Object {
+	value := new(big.Int).Neg(node.Big)
+	if !value.IsInt64() && env.IntegerOverflow() == object.ErrorOnOverflow {
+		return newError("integer overflow: -%s does not fit in INTEGER", node.Token.Literal)
+	}
+	return newInteger(value)
+}
+
+func evalIntegerInfixExpression(operator string, left, right object.Object, env *object.Environment) object.Object {
+	leftValue := left.(*object.Integer).Value
+	rightValue := right.(*object.Integer).Value
+
+	var result int64
+	var ok bool
+
+	switch operator {
+	case "+":
+		result = leftValue + rightValue
+		ok = (result > leftValue) == (rightValue > 0)
+	case "-":
+		result = leftValue - rightValue
+		ok = (result < leftValue) == (rightValue > 0)
+	case "*":
+		result = leftValue * rightValue
+		ok = leftValue == 0 || result/leftValue == rightValue && !(leftValue == -1 && rightValue == math.MinInt64)
+	case "/":
+		if rightValue == 0 {
+			return newError("division by zero")
+		}
+		result = leftValue / rightValue
+		ok = !(leftValue == math.MinInt64 && rightValue == -1)
+	case "%":
+		if rightValue == 0 {
+			return newError("division by zero")
+		}
+		result, ok = leftValue%rightValue, true
+	case "**", "<<":
+		// computed exactly, the result only has to be checked for fitting in an int64
+		exact := evalBigIntInfixExpression(operator, left, right)
+		if big, isBig := exact.(*object.BigInt); isBig {
+			return integerOverflow(env, big.Value, "%d %s %d", leftValue, operator, rightValue)
+		}
+		return exact
+	case ">>":
+		if rightValue < 0 {
+			return newError("negative shift count: %d", rightValue)
+		}
+		result, ok = leftValue>>rightValue, true
+	case "&":
+		result, ok = leftValue&rightValue, true
+	case "|":
+		result, ok = leftValue|rightValue, true
+	case "^":
+		result, ok = leftValue^rightValue, true
+	case "<":
+		return nativeBoolToBooleanObject(leftValue < rightValue)
+	case ">":
+		return nativeBoolToBooleanObject(leftValue > rightValue)
+	case "<=":
+		return nativeBoolToBooleanObject(leftValue <= rightValue)
+	case ">=":
+		return nativeBoolToBooleanObject(leftValue >= rightValue)
+	case "==":
+		return nativeBoolToBooleanObject(leftValue == rightValue)
+	case "!=":
+		return nativeBoolToBooleanObject(leftValue != rightValue)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+
+	if !ok {
+		exact := evalBigIntInfixExpression(operator, left, right).(*object.BigInt)
+		return integerOverflow(env, exact.Value, "%d %s %d", leftValue, operator, rightValue)
+	}
+	return &object.Integer{Value: result}
+}
+
+// evalBigIntInfixExpression evaluates operators on integers of arbitrary size
+func evalBigIntInfixExpression(operator string, left, right object.Object) object.Object {
+	leftValue := toBigInt(left)
+	rightValue := toBigInt(right)
+
+	switch operator {
+	case "+":
+		return newInteger(new(big.Int).Add(leftValue, rightValue))
+	case "-":
+		return newInteger(new(big.Int).Sub(leftValue, rightValue))
+	case "*":
+		return newInteger(new(big.Int).Mul(leftValue, rightValue))
+	case "/":
+		if rightValue.Sign() == 0 {
+			return newError("division by zero")
+		}
+		// Quo truncates towards zero like the int64 division
+		return newInteger(new(big.Int).Quo(leftValue, rightValue))
+	case "%":
+		if rightValue.Sign() == 0 {
+			return newError("division by zero")
+		}
+		// Rem has the sign of the dividend like the int64 remainder
+		return newInteger(new(big.Int).Rem(leftValue, rightValue))
+	case "**":
+		if rightValue.Sign() < 0 {
+			return &object.Float{Value: math.Pow(toFloat(left), toFloat(right))}
+		}
+		if rightValue.Cmp(big.NewInt(maxExponent)) > 0 && leftValue.CmpAbs(big.NewInt(1)) > 0 {
+			return newError("exponent too large: %s", rightValue)
+		}
+		return newInteger(new(big.Int).Exp(leftValue, rightValue, nil))
+	case "<<", ">>":
+		if rightValue.Sign() < 0 {
+			return newError("negative shift count: %s", rightValue)
+		}
+		if !rightValue.IsUint64() || rightValue.Uint64() > maxShift {
+			return newError("shift count too large: %s", rightValue)
+		}
+		if operator == "<<" {
+			return newInteger(new(big.Int).Lsh(leftValue, uint(rightValue.Uint64())))
+		}
+		return newInteger(new(big.Int).Rsh(leftValue, uint(rightValue.Uint64())))
+	case "&":
+		return newInteger(new(big.Int).And(leftValue, rightValue))
+	case "|":
+		return newInteger(new(big.Int).Or(leftValue, rightValue))
+	case "^":
+		return newInteger(new(big.Int).Xor(leftValue, rightValue))
+	case "<":
+		return nativeBoolToBooleanObject(leftValue.Cmp(rightValue) < 0)
+	case ">":
+		return nativeBoolToBooleanObject(leftValue.Cmp(rightValue) > 0)
+	case "<=":
+		return nativeBoolToBooleanObject(leftValue.Cmp(rightValue) <= 0)
+	case ">=":
+		return nativeBoolToBooleanObject(leftValue.Cmp(rightValue) >= 0)
+	case "==":
+		return nativeBoolToBooleanObject(leftValue.Cmp(rightValue) == 0)
+	case "!=":
+		return nativeBoolToBooleanObject(leftValue.Cmp(rightValue) != 0)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// integerOverflow returns the exact result of an operation that overflowed an int64 as a big integer,
+// or an error describing the operation if the environment does not allow big integers
+func integerOverflow(env *object.Environment, exact *big.Int, format string, a ...interface{}) object.Object {
+	if env.IntegerOverflow() == object.ErrorOnOverflow {
+		return newError("integer overflow: %s", fmt.Sprintf(format, a...))
+	}
+	return &object.BigInt{Value: exact}
+}
+
+// newInteger returns an Integer if the value fits in an int64 and a BigInt otherwise
+func newInteger(value *big.Int) object.Object {
+	if value.IsInt64() {
+		return &object.Integer{Value: value.Int64()}
+	}
+	return &object.BigInt{Value: value}
+}
+
+func isInteger(obj object.Object) bool {
+	return obj.Type() == object.INTEGER_OBJ || obj.Type() == object.BIGINT_OBJ
+}
+
+// toBigInt returns the value of an integer or big integer object as a big.Int
+func toBigInt(obj object.Object) *big.Int {
+	if integer, ok := obj.(*object.Integer); ok {
+		return big.NewInt(integer.Value)
+	}
+	return obj.(*object.BigInt).Value
+}
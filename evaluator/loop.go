@@ -0,0 +1,86 @@
+package evaluator
+
+import (
+	"monkey/ast"
+	"monkey/object"
+)
+
+// evalForStatement evaluates the body in a new scope for every element, so closures created by the body
+// capture the names of their own iteration
+func evalForStatement(fs *ast.ForStatement, env *object.Environment) object.Object {
+	iterable := Eval(fs.Iterable, env)
+	if interrupts(iterable) {
+		return iterable
+	}
+
+	// a single name iterates over the keys of a hash and the elements of everything else
+	_, keysOnly := iterable.(*object.Hash)
+	keysOnly = keysOnly && fs.Key == nil
+
+	var result object.Object
+	ok := iterate(iterable, func(key, value object.Object) bool {
+		loopEnv := object.NewEnclosedEnvironment(env)
+		switch {
+		case fs.Key != nil:
+			loopEnv.Set(fs.Key.Value, key)
+			loopEnv.Set(fs.Value.Value, value)
+		case keysOnly:
+			loopEnv.Set(fs.Value.Value, key)
+		default:
+			loopEnv.Set(fs.Value.Value, value)
+		}
+
+		switch evaluated := Eval(fs.Body, loopEnv).(type) {
+		case *object.Break:
+			return false
+		case *object.ReturnValue, *object.Error:
+			result = evaluated
+			return false
+		}
+		return true
+	})
+	if !ok {
+		return newError("cannot iterate over %s", iterable.Type())
+	}
+
+	return result
+}
+
+// iterate calls yield with the index or key and the element for every element of an array, hash, string
+// or range until yield returns false. It reports false if the object cannot be iterated over.
+//
+// The elements of arrays and hashes are those present when the iteration starts, strings are iterated
+// over by character and their index counts characters like len does.
+func iterate(obj object.Object, yield func(key, value object.Object) bool) bool {
+	switch obj := obj.(type) {
+	case *object.Array:
+		for i, element := range obj.Elements {
+			if !yield(&object.Integer{Value: int64(i)}, element) {
+				break
+			}
+		}
+	case *object.Hash:
+		for _, pair := range obj.Pairs() {
+			if !yield(pair.Key, pair.Value) {
+				break
+			}
+		}
+	case *object.String:
+		i := int64(0)
+		for _, r := range obj.Value {
+			if !yield(&object.Integer{Value: i}, &object.String{Value: string(r)}) {
+				break
+			}
+			i++
+		}
+	case *object.Range:
+		for i, length := uint64(0), obj.Len(); i < length; i++ {
+			if !yield(&object.Integer{Value: int64(i)}, &object.Integer{Value: obj.At(i)}) {
+				break
+			}
+		}
+	default:
+		return false
+	}
+	return true
+}
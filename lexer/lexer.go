@@ -1,31 +1,78 @@
 package lexer
 
 import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
 	"monkey/token"
 )
 
+// Mode controls optional behaviour of the lexer
+type Mode uint
+
+const (
+	// ScanComments returns comments as token.COMMENT tokens instead of skipping them
+	ScanComments Mode = 1 << iota
+)
+
+// messages of token.ILLEGAL tokens
+const (
+	ErrUnterminatedComment = "unterminated block comment"
+	ErrUnterminatedString  = "unterminated string literal"
+	ErrInvalidUTF8         = "invalid UTF-8 encoding"
+	ErrMalformedExponent   = "exponent has no digits"
+	ErrDigitSeparator      = "'_' must separate successive digits"
+)
+
 type Lexer struct {
 	input        string
-	position     int  // position in the input (points to current char)
-	readPosition int  // current reading position in the input (after current char)
-	ch           byte // current char under examination
+	filename     string // name of the source file, used in token positions
+	mode         Mode
+	position     int  // byte offset in the input (points to current char)
+	readPosition int  // current reading offset in the input (after current char)
+	ch           rune // current char under examination, utf8.RuneError for invalid UTF-8
+	line         int  // line of the current char, starting at 1
+	column       int  // column of the current char in runes, starting at 1
 }
 
 func New(input string) *Lexer {
+	return NewWithFilename("", input)
+}
+
+// NewWithFilename creates a lexer whose token positions refer to the given file name
+func NewWithFilename(filename string, input string) *Lexer {
 	lexer := &Lexer{
-		input: input,
+		input:    input,
+		filename: filename,
+		line:     1,
 	}
 	// read one character to initialize other variables
 	lexer.readChar()
 	return lexer
 }
 
+// SetMode changes the mode of the lexer for the following tokens
+func (l *Lexer) SetMode(mode Mode) {
+	l.mode = mode
+}
+
 // NextToken returns the next token in the input of the lexer
 func (l *Lexer) NextToken() token.Token {
 	var tok token.Token
 
-	// skip white space
+	// skip white space and comments, unless comments are requested as tokens
 	l.skipWhiteSpace()
+	for l.atComment() && l.mode&ScanComments == 0 {
+		start := l.currentPosition()
+		if _, ok := l.readComment(); !ok {
+			return token.Token{Type: token.ILLEGAL, Literal: ErrUnterminatedComment, Pos: start, End: l.currentPosition()}
+		}
+		l.skipWhiteSpace()
+	}
+
+	start := l.currentPosition()
 
 	switch l.ch {
 	case '=':
@@ -37,6 +84,8 @@ func (l *Lexer) NextToken() token.Token {
 		}
 	case ';':
 		tok = newToken(token.SEMICOLON, l.ch)
+	case ':':
+		tok = newToken(token.COLON, l.ch)
 	case '(':
 		tok = newToken(token.LPAREN, l.ch)
 	case ')':
@@ -47,6 +96,10 @@ func (l *Lexer) NextToken() token.Token {
 		tok = newToken(token.LBRACE, l.ch)
 	case '}':
 		tok = newToken(token.RBRACE, l.ch)
+	case '[':
+		tok = newToken(token.LBRACKET, l.ch)
+	case ']':
+		tok = newToken(token.RBRACKET, l.ch)
 	case '!':
 		if l.peekChar() == '=' {
 			l.readChar()
@@ -55,87 +108,296 @@ func (l *Lexer) NextToken() token.Token {
 			tok = newToken(token.BANG, l.ch)
 		}
 	case '+':
-		tok = newToken(token.PLUS, l.ch)
+		if l.peekChar() == '=' {
+			l.readChar()
+			tok = token.Token{Type: token.PLUS_ASSIGN, Literal: "+="}
+		} else {
+			tok = newToken(token.PLUS, l.ch)
+		}
 	case '-':
-		tok = newToken(token.MINUS, l.ch)
+		if l.peekChar() == '=' {
+			l.readChar()
+			tok = token.Token{Type: token.MINUS_ASSIGN, Literal: "-="}
+		} else {
+			tok = newToken(token.MINUS, l.ch)
+		}
 	case '*':
-		tok = newToken(token.ASTERISK, l.ch)
+		switch l.peekChar() {
+		case '*':
+			l.readChar()
+			tok = token.Token{Type: token.POWER, Literal: "**"}
+		case '=':
+			l.readChar()
+			tok = token.Token{Type: token.ASTERISK_ASSIGN, Literal: "*="}
+		default:
+			tok = newToken(token.ASTERISK, l.ch)
+		}
+	case '%':
+		tok = newToken(token.PERCENT, l.ch)
+	case '&':
+		if l.peekChar() == '&' {
+			l.readChar()
+			tok = token.Token{Type: token.AND, Literal: "&&"}
+		} else {
+			tok = newToken(token.AMPERSAND, l.ch)
+		}
+	case '|':
+		if l.peekChar() == '|' {
+			l.readChar()
+			tok = token.Token{Type: token.OR, Literal: "||"}
+		} else {
+			tok = newToken(token.PIPE, l.ch)
+		}
+	case '^':
+		tok = newToken(token.CARET, l.ch)
 	case '/':
-		tok = newToken(token.SLASH, l.ch)
+		if l.atComment() {
+			comment, ok := l.readComment()
+			tok = token.Token{Type: token.COMMENT, Literal: comment, Pos: start, End: l.currentPosition()}
+			if !ok {
+				tok.Type, tok.Literal = token.ILLEGAL, ErrUnterminatedComment
+			}
+			// early return to avoid calling readChar again
+			return tok
+		}
+		if l.peekChar() == '=' {
+			l.readChar()
+			tok = token.Token{Type: token.SLASH_ASSIGN, Literal: "/="}
+		} else {
+			tok = newToken(token.SLASH, l.ch)
+		}
 	case '<':
-		tok = newToken(token.LT, l.ch)
+		switch l.peekChar() {
+		case '=':
+			l.readChar()
+			tok = token.Token{Type: token.LT_EQ, Literal: "<="}
+		case '<':
+			l.readChar()
+			tok = token.Token{Type: token.SHIFT_LEFT, Literal: "<<"}
+		default:
+			tok = newToken(token.LT, l.ch)
+		}
 	case '>':
-		tok = newToken(token.GT, l.ch)
-	case '"':
+		switch l.peekChar() {
+		case '=':
+			l.readChar()
+			tok = token.Token{Type: token.GT_EQ, Literal: ">="}
+		case '>':
+			l.readChar()
+			tok = token.Token{Type: token.SHIFT_RIGHT, Literal: ">>"}
+		default:
+			tok = newToken(token.GT, l.ch)
+		}
+	case '"', '`':
 		tok.Type = token.STRING
-		tok.Literal = l.readString()
+		literal, problem := l.readString(l.ch)
+		tok.Literal = literal
+		if problem != "" {
+			tok.Type, tok.Literal = token.ILLEGAL, problem
+		}
 	case 0:
 		tok.Type = token.EOF
 		tok.Literal = ""
 	default:
-		if isLetter(l.ch) {
+		if l.atInvalidUTF8() {
+			tok.Type = token.ILLEGAL
+			tok.Literal = ErrInvalidUTF8
+		} else if isLetter(l.ch) {
 			tok.Literal = l.readIdentifier()
 			tok.Type = token.LookupIdentifier(tok.Literal)
+			tok.Pos, tok.End = start, l.currentPosition()
 			// early return to avoid calling readChar again
 			return tok
-		} else if isDigit(l.ch) {
-			tok.Type = token.INT
-			tok.Literal = l.readNumber()
+		} else if isDigit(l.ch) || l.ch == '.' && isDigit(l.peekChar()) {
+			tok.Type, tok.Literal = l.readNumber()
+			tok.Pos, tok.End = start, l.currentPosition()
 			// early return to avoid calling readChar again
 			return tok
 		} else {
-			tok = newToken(token.ILLEGAL, l.ch)
+			tok.Type = token.ILLEGAL
+			tok.Literal = fmt.Sprintf("unexpected character %q", l.ch)
 		}
 	}
 
 	l.readChar()
+	tok.Pos, tok.End = start, l.currentPosition()
 
 	return tok
 }
 
-// readChar sets the current character and advances position and readPosition to the next char
+// readChar decodes the next character and advances position and readPosition past it
 func (l *Lexer) readChar() {
+	if l.readPosition > len(l.input) {
+		// already at the end of the input, keep the position stable
+		return
+	}
+
+	if l.ch == '\n' {
+		l.line += 1
+		l.column = 0
+	}
+
+	width := 1
 	if l.readPosition >= len(l.input) {
 		l.ch = 0 // ASCII for NUL character
 	} else {
-		l.ch = l.input[l.readPosition]
+		l.ch, width = utf8.DecodeRuneInString(l.input[l.readPosition:])
 	}
 	l.position = l.readPosition
-	l.readPosition += 1
+	l.readPosition += width
+	l.column += 1
+}
+
+// peekChar looks at the character ahead of current character if possible
+func (l *Lexer) peekChar() rune {
+	if l.readPosition >= len(l.input) {
+		return 0
+	}
+	ch, _ := utf8.DecodeRuneInString(l.input[l.readPosition:])
+	return ch
+}
+
+// atInvalidUTF8 reports whether the current char is a byte that is not valid UTF-8, as opposed to
+// a correctly encoded U+FFFD
+func (l *Lexer) atInvalidUTF8() bool {
+	return l.ch == utf8.RuneError && l.readPosition-l.position == 1
+}
+
+// currentPosition returns the source position of the current char
+func (l *Lexer) currentPosition() token.Position {
+	return token.Position{
+		Filename: l.filename,
+		Offset:   l.position,
+		Line:     l.line,
+		Column:   l.column,
+	}
 }
 
 // newToken is a helper method for creating token from type and a single character (does not work for all token types)
-func newToken(tokenType token.TokenType, ch byte) token.Token {
+func newToken(tokenType token.TokenType, ch rune) token.Token {
 	return token.Token{Type: tokenType, Literal: string(ch)}
 }
 
-// readIdentifier reads an identifier until it encounters a non-letter character
+// readIdentifier reads an identifier until it encounters a character that is neither a letter nor a digit
 func (l *Lexer) readIdentifier() string {
 	start := l.position
-	for isLetter(l.ch) {
+	for isLetter(l.ch) || unicode.IsDigit(l.ch) {
 		l.readChar()
 	}
 	return l.input[start:l.position]
 }
 
-func (l *Lexer) readNumber() string {
+// numberBases maps the prefix letters of integer literals to their base
+var numberBases = map[rune]int{'x': 16, 'X': 16, 'o': 8, 'O': 8, 'b': 2, 'B': 2}
+
+var baseNames = map[int]string{16: "hexadecimal", 10: "decimal", 8: "octal", 2: "binary"}
+
+// readNumber reads an integer or a float literal. Integers may have a 0x, 0o or 0b prefix, floats an
+// optional fraction and exponent, e.g. 3.14, .5 or 1e-9, and digits may be separated by underscores.
+// Malformed literals are consumed completely and returned as an illegal token describing the problem.
+func (l *Lexer) readNumber() (token.TokenType, string) {
 	start := l.position
-	for isDigit(l.ch) {
+
+	if base, ok := numberBases[l.peekChar()]; ok && l.ch == '0' {
+		l.readChar()
+		l.readChar()
+		var problem string
+		if l.readDigits(base, &problem) == 0 {
+			return token.ILLEGAL, baseNames[base] + " literal has no digits"
+		}
+		if problem != "" {
+			return token.ILLEGAL, problem
+		}
+		return token.INT, l.input[start:l.position]
+	}
+
+	tokenType := token.TokenType(token.INT)
+	var problem string
+	l.readDigits(10, &problem)
+	if l.ch == '.' && isDigit(l.peekChar()) {
+		tokenType = token.FLOAT
+		l.readChar()
+		l.readDigits(10, &problem)
+	}
+	if l.ch == 'e' || l.ch == 'E' {
+		tokenType = token.FLOAT
+		l.readChar()
+		if l.ch == '+' || l.ch == '-' {
+			l.readChar()
+		}
+		if !isDigit(l.ch) {
+			return token.ILLEGAL, ErrMalformedExponent
+		}
+		l.readDigits(10, &problem)
+	}
+
+	if problem != "" {
+		return token.ILLEGAL, problem
+	}
+	return tokenType, l.input[start:l.position]
+}
+
+// readDigits consumes the digits of a number in the given base, digits may be separated by single
+// underscores. It returns the number of digits and stores a description of the first problem found in
+// problem unless that already holds one.
+func (l *Lexer) readDigits(base int, problem *string) int {
+	count := 0
+	underscore := false // the previous char was an underscore
+	report := func(msg string) {
+		if *problem == "" {
+			*problem = msg
+		}
+	}
+
+	for {
+		switch {
+		case l.ch == '_':
+			if underscore {
+				report(ErrDigitSeparator)
+			}
+			underscore = true
+		case isDigit(l.ch) || base == 16 && isHexDigit(l.ch):
+			if int(hexValue(l.ch)) >= base {
+				report(fmt.Sprintf("invalid digit %q in %s literal", l.ch, baseNames[base]))
+			}
+			count += 1
+			underscore = false
+		default:
+			if underscore {
+				report(ErrDigitSeparator)
+			}
+			return count
+		}
 		l.readChar()
 	}
-	return l.input[start:l.position]
 }
 
 // isLetter is a helper function for reading identifiers, it checks if a character is part of an identifier
-func isLetter(ch byte) bool {
-	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_'
+func isLetter(ch rune) bool {
+	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_' ||
+		ch >= utf8.RuneSelf && unicode.IsLetter(ch)
 }
 
-// isLetter is a helper function for reading numbers, only supports integers
-func isDigit(ch byte) bool {
+// isDigit is a helper function for reading numbers, only ASCII digits are accepted
+func isDigit(ch rune) bool {
 	return '0' <= ch && ch <= '9'
 }
 
+func isHexDigit(ch rune) bool {
+	return isDigit(ch) || 'a' <= ch && ch <= 'f' || 'A' <= ch && ch <= 'F'
+}
+
+func hexValue(ch rune) rune {
+	switch {
+	case isDigit(ch):
+		return ch - '0'
+	case 'a' <= ch && ch <= 'f':
+		return ch - 'a' + 10
+	default:
+		return ch - 'A' + 10
+	}
+}
+
 // skipWhiteSpace consumes whitespace until it encounters a valid character
 func (l *Lexer) skipWhiteSpace() {
 	for l.ch == ' ' || l.ch == '\t' || l.ch == '\n' || l.ch == '\r' {
@@ -143,22 +405,116 @@ func (l *Lexer) skipWhiteSpace() {
 	}
 }
 
-// peekChar looks at the character ahead of current character if possible
-func (l *Lexer) peekChar() byte {
-	if l.readPosition >= len(l.input) {
-		return 0
-	} else {
-		return l.input[l.readPosition]
+// atComment reports whether a comment starts at the current char
+func (l *Lexer) atComment() bool {
+	return l.ch == '/' && (l.peekChar() == '/' || l.peekChar() == '*')
+}
+
+// readComment reads a // line comment or a /* block comment */, block comments may be nested.
+// It reports false if the end of the input is reached inside a block comment.
+func (l *Lexer) readComment() (string, bool) {
+	start := l.position
+
+	if l.peekChar() == '/' {
+		for l.ch != '\n' && l.ch != 0 {
+			l.readChar()
+		}
+		return l.input[start:l.position], true
+	}
+
+	depth := 0
+	for {
+		switch {
+		case l.ch == 0:
+			return l.input[start:l.position], false
+		case l.ch == '/' && l.peekChar() == '*':
+			depth += 1
+			l.readChar()
+		case l.ch == '*' && l.peekChar() == '/':
+			depth -= 1
+			l.readChar()
+			if depth == 0 {
+				l.readChar()
+				return l.input[start:l.position], true
+			}
+		}
+		l.readChar()
 	}
 }
 
-func (l *Lexer) readString() string {
-	position := l.position + 1
+// readString reads a string literal delimited by quote and returns its value. Escape sequences are
+// decoded in double quoted strings, backtick quoted raw strings are taken as they are. If the literal
+// is not terminated or has a bad escape sequence, the returned problem describes the first one.
+func (l *Lexer) readString(quote rune) (value, problem string) {
+	var out strings.Builder
+
 	for {
 		l.readChar()
-		if l.ch == '"' || l.ch == 0 {
-			break
+		switch {
+		case l.ch == 0:
+			return out.String(), ErrUnterminatedString
+		case l.ch == quote:
+			return out.String(), problem
+		case l.ch == '\\' && quote == '"':
+			l.readChar()
+			if p := l.readEscape(&out); p != "" && problem == "" {
+				problem = p
+			}
+		case l.atInvalidUTF8():
+			if problem == "" {
+				problem = ErrInvalidUTF8 + " in string literal"
+			}
+		default:
+			out.WriteRune(l.ch)
 		}
 	}
-	return l.input[position:l.position]
+}
+
+// readEscape decodes the escape sequence starting at the current char, which follows the backslash,
+// into out. It returns a description of the problem if the sequence is invalid.
+func (l *Lexer) readEscape(out *strings.Builder) string {
+	switch l.ch {
+	case 'n':
+		out.WriteByte('\n')
+	case 't':
+		out.WriteByte('\t')
+	case '\\', '"':
+		out.WriteRune(l.ch)
+	case 'x':
+		if !isHexDigit(l.peekChar()) {
+			return "invalid escape sequence \\x, want two hex digits"
+		}
+		l.readChar()
+		value := hexValue(l.ch)
+		if !isHexDigit(l.peekChar()) {
+			return "invalid escape sequence \\x, want two hex digits"
+		}
+		l.readChar()
+		out.WriteByte(byte(value*16 + hexValue(l.ch)))
+	case 'u':
+		if l.peekChar() != '{' {
+			return "invalid escape sequence \\u, want \\u{...}"
+		}
+		l.readChar()
+		var value rune
+		digits := 0
+		for isHexDigit(l.peekChar()) && digits <= 6 {
+			l.readChar()
+			value = value*16 + hexValue(l.ch)
+			digits += 1
+		}
+		if digits == 0 || digits > 6 || l.peekChar() != '}' {
+			return "invalid escape sequence \\u, want 1 to 6 hex digits in braces"
+		}
+		l.readChar()
+		if !utf8.ValidRune(value) {
+			return fmt.Sprintf("invalid code point U+%04X in escape sequence", value)
+		}
+		out.WriteRune(value)
+	case 0:
+		// the caller reports the unterminated string
+	default:
+		return fmt.Sprintf("unknown escape sequence \\%c", l.ch)
+	}
+	return ""
 }
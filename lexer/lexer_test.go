@@ -29,7 +29,7 @@ let add = fn(x, y) {
 };
 let result = add(five, ten);
 
-!-/*5;
+!-/ *5;
 5 < 10 > 5;
 
 if (5 < 10) {
@@ -43,6 +43,8 @@ if (5 < 10) {
 
 "foobar"
 "foo bar"
+[1, 2];
+{"foo": "bar"}
 `
 
 	tests := []struct {
@@ -124,6 +126,17 @@ if (5 < 10) {
 		{token.SEMICOLON, ";"},
 		{token.STRING, "foobar"},
 		{token.STRING, "foo bar"},
+		{token.LBRACKET, "["},
+		{token.INT, "1"},
+		{token.COMMA, ","},
+		{token.INT, "2"},
+		{token.RBRACKET, "]"},
+		{token.SEMICOLON, ";"},
+		{token.LBRACE, "{"},
+		{token.STRING, "foo"},
+		{token.COLON, ":"},
+		{token.STRING, "bar"},
+		{token.RBRACE, "}"},
 		{token.EOF, ""},
 	}
 
@@ -136,3 +149,279 @@ if (5 < 10) {
 		s.Require().Equal(tt.expectedLiteral, tok.Literal, i)
 	}
 }
+
+func (s *Suite) TestTokenPositions() {
+	input := "let x = 5;\n  x + \"ab\";"
+
+	tests := []struct {
+		expectedType token.TokenType
+		expectedPos  token.Position
+		expectedEnd  token.Position
+	}{
+		{token.LET, token.Position{Filename: "test.mk", Offset: 0, Line: 1, Column: 1}, token.Position{Filename: "test.mk", Offset: 3, Line: 1, Column: 4}},
+		{token.IDENT, token.Position{Filename: "test.mk", Offset: 4, Line: 1, Column: 5}, token.Position{Filename: "test.mk", Offset: 5, Line: 1, Column: 6}},
+		{token.ASSIGN, token.Position{Filename: "test.mk", Offset: 6, Line: 1, Column: 7}, token.Position{Filename: "test.mk", Offset: 7, Line: 1, Column: 8}},
+		{token.INT, token.Position{Filename: "test.mk", Offset: 8, Line: 1, Column: 9}, token.Position{Filename: "test.mk", Offset: 9, Line: 1, Column: 10}},
+		{token.SEMICOLON, token.Position{Filename: "test.mk", Offset: 9, Line: 1, Column: 10}, token.Position{Filename: "test.mk", Offset: 10, Line: 1, Column: 11}},
+		{token.IDENT, token.Position{Filename: "test.mk", Offset: 13, Line: 2, Column: 3}, token.Position{Filename: "test.mk", Offset: 14, Line: 2, Column: 4}},
+		{token.PLUS, token.Position{Filename: "test.mk", Offset: 15, Line: 2, Column: 5}, token.Position{Filename: "test.mk", Offset: 16, Line: 2, Column: 6}},
+		{token.STRING, token.Position{Filename: "test.mk", Offset: 17, Line: 2, Column: 7}, token.Position{Filename: "test.mk", Offset: 21, Line: 2, Column: 11}},
+		{token.SEMICOLON, token.Position{Filename: "test.mk", Offset: 21, Line: 2, Column: 11}, token.Position{Filename: "test.mk", Offset: 22, Line: 2, Column: 12}},
+		{token.EOF, token.Position{Filename: "test.mk", Offset: 22, Line: 2, Column: 12}, token.Position{Filename: "test.mk", Offset: 22, Line: 2, Column: 12}},
+		{token.EOF, token.Position{Filename: "test.mk", Offset: 22, Line: 2, Column: 12}, token.Position{Filename: "test.mk", Offset: 22, Line: 2, Column: 12}},
+	}
+
+	lex := lexer.NewWithFilename("test.mk", input)
+
+	for i, tt := range tests {
+		tok := lex.NextToken()
+
+		s.Require().Equal(tt.expectedType, tok.Type, i)
+		s.Require().Equal(tt.expectedPos, tok.Pos, i)
+		s.Require().Equal(tt.expectedEnd, tok.End, i)
+	}
+}
+
+func (s *Suite) TestOperators() {
+	input := "<= >= && || % ** & | ^ << >> < > * = += -= *= /= /"
+
+	expected := []token.TokenType{
+		token.LT_EQ, token.GT_EQ, token.AND, token.OR, token.PERCENT, token.POWER,
+		token.AMPERSAND, token.PIPE, token.CARET, token.SHIFT_LEFT, token.SHIFT_RIGHT,
+		token.LT, token.GT, token.ASTERISK, token.ASSIGN,
+		token.PLUS_ASSIGN, token.MINUS_ASSIGN, token.ASTERISK_ASSIGN, token.SLASH_ASSIGN, token.SLASH, token.EOF,
+	}
+
+	lex := lexer.New(input)
+
+	for i, tt := range expected {
+		tok := lex.NextToken()
+		s.Require().Equal(tt, tok.Type, i)
+		if tt != token.EOF {
+			s.Require().Equal(string(tt), tok.Literal, i)
+		}
+	}
+}
+
+func (s *Suite) TestComments() {
+	input := `// leading comment
+let x = 5; // trailing comment
+/* block /* nested */ still comment */ x / 2;
+/**/ x`
+
+	expected := []token.TokenType{
+		token.LET, token.IDENT, token.ASSIGN, token.INT, token.SEMICOLON,
+		token.IDENT, token.SLASH, token.INT, token.SEMICOLON,
+		token.IDENT, token.EOF,
+	}
+
+	lex := lexer.New(input)
+
+	for i, tt := range expected {
+		s.Require().Equal(tt, lex.NextToken().Type, i)
+	}
+}
+
+func (s *Suite) TestScanComments() {
+	input := "// line\nx /* a /* b */ c */;\n/**/"
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+		expectedPos     string
+		expectedEnd     string
+	}{
+		{token.COMMENT, "// line", "1:1", "1:8"},
+		{token.IDENT, "x", "2:1", "2:2"},
+		{token.COMMENT, "/* a /* b */ c */", "2:3", "2:20"},
+		{token.SEMICOLON, ";", "2:20", "2:21"},
+		{token.COMMENT, "/**/", "3:1", "3:5"},
+		{token.EOF, "", "3:5", "3:5"},
+	}
+
+	lex := lexer.New(input)
+	lex.SetMode(lexer.ScanComments)
+
+	for i, tt := range tests {
+		tok := lex.NextToken()
+
+		s.Require().Equal(tt.expectedType, tok.Type, i)
+		s.Require().Equal(tt.expectedLiteral, tok.Literal, i)
+		s.Require().Equal(tt.expectedPos, tok.Pos.String(), i)
+		s.Require().Equal(tt.expectedEnd, tok.End.String(), i)
+	}
+}
+
+func (s *Suite) TestUnterminatedComment() {
+	for _, mode := range []lexer.Mode{0, lexer.ScanComments} {
+		lex := lexer.New("x /* a /* b */")
+		lex.SetMode(mode)
+
+		s.Require().Equal(token.TokenType(token.IDENT), lex.NextToken().Type)
+
+		tok := lex.NextToken()
+		s.Require().Equal(token.TokenType(token.ILLEGAL), tok.Type)
+		s.Require().Equal(lexer.ErrUnterminatedComment, tok.Literal)
+		s.Require().Equal("1:3", tok.Pos.String())
+		s.Require().Equal(token.TokenType(token.EOF), lex.NextToken().Type)
+	}
+}
+
+func (s *Suite) TestStrings() {
+	tests := []struct {
+		input           string
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{`"say \"hi\"\n"`, token.STRING, "say \"hi\"\n"},
+		{`"a\tb\\c"`, token.STRING, "a\tb\\c"},
+		{`"\x41\x7a"`, token.STRING, "Az"},
+		{`"\u{e9}\u{1F600}"`, token.STRING, "é😀"},
+		{"\"multi\nline\"", token.STRING, "multi\nline"},
+		{"`raw \\n \"quotes\"`", token.STRING, `raw \n "quotes"`},
+		{"``", token.STRING, ""},
+		{`"unterminated`, token.ILLEGAL, lexer.ErrUnterminatedString},
+		{`"ends in escape\`, token.ILLEGAL, lexer.ErrUnterminatedString},
+		{"`unterminated", token.ILLEGAL, lexer.ErrUnterminatedString},
+		{`"\q"`, token.ILLEGAL, `unknown escape sequence \q`},
+		{`"\x4"`, token.ILLEGAL, `invalid escape sequence \x, want two hex digits`},
+		{`"\u41"`, token.ILLEGAL, `invalid escape sequence \u, want \u{...}`},
+		{`"\u{}"`, token.ILLEGAL, `invalid escape sequence \u, want 1 to 6 hex digits in braces`},
+		{`"\u{1234567}"`, token.ILLEGAL, `invalid escape sequence \u, want 1 to 6 hex digits in braces`},
+		{`"\u{D800}"`, token.ILLEGAL, `invalid code point U+D800 in escape sequence`},
+	}
+
+	for _, tt := range tests {
+		lex := lexer.New(tt.input + ";")
+
+		tok := lex.NextToken()
+		s.Require().Equal(tt.expectedType, tok.Type, tt.input)
+		s.Require().Equal(tt.expectedLiteral, tok.Literal, tt.input)
+		s.Require().Equal(0, tok.Pos.Offset, tt.input)
+
+		if tt.expectedLiteral != lexer.ErrUnterminatedString {
+			// the whole literal is consumed, even after a bad escape sequence
+			s.Require().Equal(len(tt.input), tok.End.Offset, tt.input)
+			s.Require().Equal(token.TokenType(token.SEMICOLON), lex.NextToken().Type, tt.input)
+		}
+	}
+}
+
+func (s *Suite) TestNumbers() {
+	tests := []struct {
+		input           string
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{"42", token.INT, "42"},
+		{"3.14", token.FLOAT, "3.14"},
+		{".5", token.FLOAT, ".5"},
+		{"1e-9", token.FLOAT, "1e-9"},
+		{"2.5E+3", token.FLOAT, "2.5E+3"},
+		{"1e", token.ILLEGAL, lexer.ErrMalformedExponent},
+		{"1e+", token.ILLEGAL, lexer.ErrMalformedExponent},
+		{"0xFF", token.INT, "0xFF"},
+		{"0X_dead_BEEF", token.INT, "0X_dead_BEEF"},
+		{"0o755", token.INT, "0o755"},
+		{"0b1010_1010", token.INT, "0b1010_1010"},
+		{"1_000_000", token.INT, "1_000_000"},
+		{"1_000.000_1e1_0", token.FLOAT, "1_000.000_1e1_0"},
+		{"0x", token.ILLEGAL, "hexadecimal literal has no digits"},
+		{"0b_", token.ILLEGAL, "binary literal has no digits"},
+		{"1__0", token.ILLEGAL, lexer.ErrDigitSeparator},
+		{"1_", token.ILLEGAL, lexer.ErrDigitSeparator},
+		{"0x1_", token.ILLEGAL, lexer.ErrDigitSeparator},
+		{"1_.5", token.ILLEGAL, lexer.ErrDigitSeparator},
+		{"0b102", token.ILLEGAL, "invalid digit '2' in binary literal"},
+		{"0o78", token.ILLEGAL, "invalid digit '8' in octal literal"},
+	}
+
+	for _, tt := range tests {
+		lex := lexer.New(tt.input + ";")
+
+		tok := lex.NextToken()
+		s.Require().Equal(tt.expectedType, tok.Type, tt.input)
+		s.Require().Equal(tt.expectedLiteral, tok.Literal, tt.input)
+		s.Require().Equal(len(tt.input), tok.End.Offset, tt.input)
+		s.Require().Equal(token.TokenType(token.SEMICOLON), lex.NextToken().Type, tt.input)
+	}
+
+	// a dot without digits after it does not belong to the number
+	lex := lexer.New("1.x")
+	s.Require().Equal(token.TokenType(token.INT), lex.NextToken().Type)
+	s.Require().Equal("unexpected character '.'", lex.NextToken().Literal)
+}
+
+func (s *Suite) TestLoopKeywords() {
+	lex := lexer.New("while break continue for in whiles")
+
+	for _, expected := range []token.TokenType{token.WHILE, token.BREAK, token.CONTINUE, token.FOR, token.IN, token.IDENT} {
+		s.Require().Equal(expected, lex.NextToken().Type)
+	}
+}
+
+func (s *Suite) TestIllegalCharacter() {
+	tok := lexer.New("@").NextToken()
+
+	s.Require().Equal(token.TokenType(token.ILLEGAL), tok.Type)
+	s.Require().Equal("unexpected character '@'", tok.Literal)
+}
+
+func (s *Suite) TestUnicode() {
+	input := "let größe = \"😀 ok\";\nx1 + größe; π"
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+		expectedPos     string
+		expectedOffset  int
+	}{
+		{token.LET, "let", "1:1", 0},
+		{token.IDENT, "größe", "1:5", 4},
+		{token.ASSIGN, "=", "1:11", 12},
+		{token.STRING, "😀 ok", "1:13", 14},
+		{token.SEMICOLON, ";", "1:19", 23},
+		{token.IDENT, "x1", "2:1", 25},
+		{token.PLUS, "+", "2:4", 28},
+		{token.IDENT, "größe", "2:6", 30},
+		{token.SEMICOLON, ";", "2:11", 37},
+		{token.IDENT, "π", "2:13", 39},
+		{token.EOF, "", "2:14", 41},
+	}
+
+	lex := lexer.New(input)
+
+	for i, tt := range tests {
+		tok := lex.NextToken()
+
+		s.Require().Equal(tt.expectedType, tok.Type, i)
+		s.Require().Equal(tt.expectedLiteral, tok.Literal, i)
+		s.Require().Equal(tt.expectedPos, tok.Pos.String(), i)
+		s.Require().Equal(tt.expectedOffset, tok.Pos.Offset, i)
+	}
+}
+
+func (s *Suite) TestInvalidUTF8() {
+	lex := lexer.New("x \xff y \"a\xc3\"")
+
+	s.Require().Equal(token.TokenType(token.IDENT), lex.NextToken().Type)
+
+	tok := lex.NextToken()
+	s.Require().Equal(token.TokenType(token.ILLEGAL), tok.Type)
+	s.Require().Equal(lexer.ErrInvalidUTF8, tok.Literal)
+	s.Require().Equal("1:3", tok.Pos.String())
+	s.Require().Equal("1:4", tok.End.String())
+
+	tok = lex.NextToken()
+	s.Require().Equal(token.TokenType(token.IDENT), tok.Type)
+	s.Require().Equal("1:5", tok.Pos.String())
+
+	tok = lex.NextToken()
+	s.Require().Equal(token.TokenType(token.ILLEGAL), tok.Type)
+	s.Require().Equal(lexer.ErrInvalidUTF8+" in string literal", tok.Literal)
+	s.Require().Equal(token.TokenType(token.EOF), lex.NextToken().Type)
+
+	// a correctly encoded replacement character is a letter-less symbol, not an encoding error
+	tok = lexer.New("�").NextToken()
+	s.Require().Equal("unexpected character '�'", tok.Literal)
+}
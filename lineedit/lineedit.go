@@ -0,0 +1,480 @@
+// Package lineedit reads lines from an interactive terminal with cursor movement, history, reverse
+// search and tab completion
+package lineedit
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// ErrInterrupted is returned by ReadLine when the user presses Ctrl-C
+var ErrInterrupted = errors.New("interrupted")
+
+// MaxHistory is the number of history entries that are kept
+const MaxHistory = 1000
+
+// CompleteFunc returns the candidates that can replace the word before the cursor together with the
+// index in line where that word starts
+type CompleteFunc func(line []rune, pos int) (candidates []string, start int)
+
+// key codes sent by the terminal
+const (
+	keyCtrlA     = 1
+	keyCtrlB     = 2
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyCtrlE     = 5
+	keyCtrlF     = 6
+	keyCtrlG     = 7
+	keyBackspace = 8
+	keyTab       = 9
+	keyLineFeed  = 10
+	keyCtrlK     = 11
+	keyCtrlL     = 12
+	keyEnter     = 13
+	keyCtrlN     = 14
+	keyCtrlP     = 16
+	keyCtrlR     = 18
+	keyCtrlU     = 21
+	keyCtrlW     = 23
+	keyEscape    = 27
+	keyDelete    = 127
+
+	// escape sequences are mapped to codes outside the unicode range
+	keyUp = unicode.MaxRune + 1 + iota
+	keyDown
+	keyLeft
+	keyRight
+	keyHome
+	keyEnd
+	keyDeleteRight
+	keyUnknown
+)
+
+// Editor reads lines from a terminal
+type Editor struct {
+	in  *os.File
+	out io.Writer
+	// reader buffers in across calls of ReadLine, input read ahead of the current line, like the rest
+	// of a pasted program, is kept for the next lines
+	reader *bufio.Reader
+
+	// Complete is called when tab is pressed, completion is disabled if it is nil
+	Complete CompleteFunc
+	// Highlight returns the line decorated with escape sequences for display, it must not change
+	// the visible text
+	Highlight func(line string) string
+
+	history     []string
+	historyFile *os.File
+}
+
+// New returns an editor reading from the terminal in, it fails if in is not a terminal
+func New(in *os.File, out io.Writer) (*Editor, error) {
+	if !isTerminal(in.Fd()) {
+		return nil, fmt.Errorf("%s is not a terminal", in.Name())
+	}
+	return &Editor{in: in, out: out, reader: bufio.NewReader(in)}, nil
+}
+
+// ReadLine shows the prompt and returns the line entered by the user. It returns io.EOF when Ctrl-D is
+// pressed on an empty line and ErrInterrupted on Ctrl-C.
+func (e *Editor) ReadLine(prompt string) (string, error) {
+	state, err := makeRaw(e.in.Fd())
+	if err != nil {
+		return "", err
+	}
+	defer restore(e.in.Fd(), state)
+
+	return e.readLine(prompt, e.reader)
+}
+
+// History returns the history entries, oldest first
+func (e *Editor) History() []string {
+	return e.history
+}
+
+// AddHistory appends the line to the history and to the history file, if one is open
+func (e *Editor) AddHistory(line string) {
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	if len(e.history) > 0 && e.history[len(e.history)-1] == line {
+		return
+	}
+
+	e.history = append(e.history, line)
+	if len(e.history) > MaxHistory {
+		e.history = e.history[len(e.history)-MaxHistory:]
+	}
+
+	if e.historyFile != nil {
+		fmt.Fprintln(e.historyFile, line)
+	}
+}
+
+// OpenHistory loads the history from the file at path and appends new entries to it, the file is created
+// if it does not exist and rewritten with the last MaxHistory entries if it has more
+func (e *Editor) OpenHistory(path string) error {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return err
+	}
+
+	lines := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines++
+		e.AddHistory(scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		file.Close()
+		return err
+	}
+
+	if lines > MaxHistory {
+		file.Close()
+		if err := writeHistory(path, e.history); err != nil {
+			return err
+		}
+		if file, err = os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o600); err != nil {
+			return err
+		}
+	}
+
+	e.historyFile = file
+	return nil
+}
+
+// writeHistory replaces the file at path with the entries, the file is written next to it first so the
+// history is not lost if writing fails
+func writeHistory(path string, entries []string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	for _, entry := range entries {
+		fmt.Fprintln(w, entry)
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Close closes the history file
+func (e *Editor) Close() error {
+	if e.historyFile == nil {
+		return nil
+	}
+	err := e.historyFile.Close()
+	e.historyFile = nil
+	return err
+}
+
+// lineState is the state of the line being edited
+type lineState struct {
+	prompt  string
+	buf     []rune
+	pos     int // cursor position in buf
+	history int // index of the history entry shown, len(history) for the new line
+	pending string
+
+	// reverse search
+	searching   bool
+	query       []rune
+	searchIndex int // index of the matching history entry, -1 if nothing matches
+}
+
+func (e *Editor) readLine(prompt string, r *bufio.Reader) (string, error) {
+	s := &lineState{prompt: prompt, history: len(e.history)}
+	e.refresh(s)
+
+	for {
+		key, err := readKey(r)
+		if err != nil {
+			return "", err
+		}
+
+		if s.searching {
+			done := e.searchKey(s, key)
+			if !done {
+				continue
+			}
+		}
+
+		switch key {
+		case keyEnter, keyLineFeed:
+			io.WriteString(e.out, "\r\n")
+			return string(s.buf), nil
+		case keyCtrlC:
+			io.WriteString(e.out, "^C\r\n")
+			return "", ErrInterrupted
+		case keyCtrlD:
+			if len(s.buf) == 0 {
+				io.WriteString(e.out, "\r\n")
+				return "", io.EOF
+			}
+			e.deleteRight(s)
+		case keyBackspace, keyDelete:
+			if s.pos > 0 {
+				s.buf = append(s.buf[:s.pos-1], s.buf[s.pos:]...)
+				s.pos -= 1
+			}
+		case keyDeleteRight:
+			e.deleteRight(s)
+		case keyCtrlA, keyHome:
+			s.pos = 0
+		case keyCtrlE, keyEnd:
+			s.pos = len(s.buf)
+		case keyCtrlB, keyLeft:
+			if s.pos > 0 {
+				s.pos -= 1
+			}
+		case keyCtrlF, keyRight:
+			if s.pos < len(s.buf) {
+				s.pos += 1
+			}
+		case keyCtrlK:
+			s.buf = s.buf[:s.pos]
+		case keyCtrlU:
+			s.buf = append([]rune{}, s.buf[s.pos:]...)
+			s.pos = 0
+		case keyCtrlW:
+			start := wordStart(s.buf, s.pos)
+			s.buf = append(s.buf[:start], s.buf[s.pos:]...)
+			s.pos = start
+		case keyCtrlL:
+			io.WriteString(e.out, "\x1b[H\x1b[2J")
+		case keyCtrlP, keyUp:
+			e.showHistory(s, s.history-1)
+		case keyCtrlN, keyDown:
+			e.showHistory(s, s.history+1)
+		case keyCtrlR:
+			s.searching = true
+			s.query = nil
+			s.searchIndex = len(e.history)
+			e.search(s, len(e.history)-1)
+			continue
+		case keyTab:
+			e.complete(s)
+		default:
+			if key < keyUp && unicode.IsPrint(key) {
+				s.buf = append(s.buf[:s.pos], append([]rune{key}, s.buf[s.pos:]...)...)
+				s.pos += 1
+			}
+		}
+
+		e.refresh(s)
+	}
+}
+
+func (e *Editor) deleteRight(s *lineState) {
+	if s.pos < len(s.buf) {
+		s.buf = append(s.buf[:s.pos], s.buf[s.pos+1:]...)
+	}
+}
+
+// showHistory replaces the line with the history entry at index, the line being typed is kept so
+// that moving past the newest entry brings it back
+func (e *Editor) showHistory(s *lineState, index int) {
+	if index < 0 || index > len(e.history) {
+		return
+	}
+	if s.history == len(e.history) {
+		s.pending = string(s.buf)
+	}
+
+	s.history = index
+	if index == len(e.history) {
+		s.buf = []rune(s.pending)
+	} else {
+		s.buf = []rune(e.history[index])
+	}
+	s.pos = len(s.buf)
+}
+
+// searchKey handles a key during reverse search, it reports whether the search is finished and the
+// key should be handled as a normal key
+func (e *Editor) searchKey(s *lineState, key rune) bool {
+	switch {
+	case key == keyCtrlR:
+		e.search(s, s.searchIndex-1)
+		return false
+	case key == keyCtrlG || key == keyCtrlC:
+		s.searching = false
+		e.refresh(s)
+		return false
+	case key == keyBackspace || key == keyDelete:
+		if len(s.query) > 0 {
+			s.query = s.query[:len(s.query)-1]
+		}
+		e.search(s, len(e.history)-1)
+		return false
+	case key < keyUp && unicode.IsPrint(key):
+		s.query = append(s.query, key)
+		e.search(s, s.searchIndex)
+		return false
+	default:
+		// any other key accepts the match and is then handled as usual
+		s.searching = false
+		if s.searchIndex >= 0 && s.searchIndex < len(e.history) {
+			s.buf = []rune(e.history[s.searchIndex])
+			s.pos = len(s.buf)
+			s.history = s.searchIndex
+		}
+		return true
+	}
+}
+
+// search looks for the query in the history, starting at index and going back in time
+func (e *Editor) search(s *lineState, index int) {
+	if index >= len(e.history) {
+		index = len(e.history) - 1
+	}
+
+	s.searchIndex = -1
+	for i := index; i >= 0; i-- {
+		if strings.Contains(e.history[i], string(s.query)) {
+			s.searchIndex = i
+			break
+		}
+	}
+
+	match := ""
+	if s.searchIndex >= 0 {
+		match = e.history[s.searchIndex]
+	}
+	fmt.Fprintf(e.out, "\r(reverse-i-search)`%s': %s\x1b[K", string(s.query), match)
+}
+
+// complete replaces the word before the cursor with the longest common prefix of the candidates
+// and lists the candidates if nothing could be added
+func (e *Editor) complete(s *lineState) {
+	if e.Complete == nil {
+		return
+	}
+
+	candidates, start := e.Complete(s.buf, s.pos)
+	if len(candidates) == 0 || start < 0 || start > s.pos {
+		return
+	}
+
+	prefix := []rune(commonPrefix(candidates))
+	if len(candidates) == 1 {
+		prefix = append(prefix, ' ')
+	}
+
+	if len(prefix) > s.pos-start {
+		rest := append([]rune{}, s.buf[s.pos:]...)
+		s.buf = append(append(s.buf[:start], prefix...), rest...)
+		s.pos = start + len(prefix)
+		return
+	}
+
+	io.WriteString(e.out, "\r\n"+strings.Join(candidates, "  ")+"\r\n")
+}
+
+// refresh redraws the prompt and line and places the cursor
+func (e *Editor) refresh(s *lineState) {
+	if s.searching {
+		return
+	}
+	line := string(s.buf)
+	if e.Highlight != nil {
+		line = e.Highlight(line)
+	}
+
+	column := len([]rune(s.prompt)) + s.pos
+	fmt.Fprintf(e.out, "\r%s%s\x1b[K\r", s.prompt, line)
+	if column > 0 {
+		fmt.Fprintf(e.out, "\x1b[%dC", column)
+	}
+}
+
+// readKey reads a single key press, escape sequences for arrow and navigation keys are decoded
+func readKey(r *bufio.Reader) (rune, error) {
+	ch, _, err := r.ReadRune()
+	if err != nil || ch != keyEscape {
+		return ch, err
+	}
+
+	next, _, err := r.ReadRune()
+	if err != nil {
+		return 0, err
+	}
+	if next != '[' && next != 'O' {
+		// a lone escape, handle the following key on its own
+		return next, nil
+	}
+
+	var seq []rune
+	for {
+		ch, _, err := r.ReadRune()
+		if err != nil {
+			return 0, err
+		}
+		seq = append(seq, ch)
+		if ch >= 0x40 && ch <= 0x7e {
+			break
+		}
+	}
+
+	switch string(seq) {
+	case "A":
+		return keyUp, nil
+	case "B":
+		return keyDown, nil
+	case "C":
+		return keyRight, nil
+	case "D":
+		return keyLeft, nil
+	case "H", "1~", "7~":
+		return keyHome, nil
+	case "F", "4~", "8~":
+		return keyEnd, nil
+	case "3~":
+		return keyDeleteRight, nil
+	default:
+		return keyUnknown, nil
+	}
+}
+
+// wordStart returns the start of the word before pos, skipping spaces in front of the cursor
+func wordStart(buf []rune, pos int) int {
+	i := pos
+	for i > 0 && unicode.IsSpace(buf[i-1]) {
+		i--
+	}
+	for i > 0 && !unicode.IsSpace(buf[i-1]) {
+		i--
+	}
+	return i
+}
+
+func commonPrefix(words []string) string {
+	prefix := []rune(words[0])
+	for _, word := range words[1:] {
+		for !strings.HasPrefix(word, string(prefix)) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return string(prefix)
+}
@@ -0,0 +1,66 @@
+package lineedit
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// openPty returns the master and slave side of a new pseudo terminal
+func openPty() (master, slave *os.File, err error) {
+	master, err = os.OpenFile("/dev/ptmx", os.O_RDWR, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var unlock int32
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, master.Fd(), syscall.TIOCSPTLCK, uintptr(unsafe.Pointer(&unlock))); errno != 0 {
+		master.Close()
+		return nil, nil, errno
+	}
+	var n uint32
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, master.Fd(), syscall.TIOCGPTN, uintptr(unsafe.Pointer(&n))); errno != 0 {
+		master.Close()
+		return nil, nil, errno
+	}
+
+	slave, err = os.OpenFile(fmt.Sprintf("/dev/pts/%d", n), os.O_RDWR, 0)
+	if err != nil {
+		master.Close()
+		return nil, nil, err
+	}
+	return master, slave, nil
+}
+
+func (s *Suite) TestReadLineKeepsPastedLines() {
+	master, slave, err := openPty()
+	if err != nil {
+		s.T().Skipf("no pseudo terminal: %v", err)
+	}
+	defer master.Close()
+	defer slave.Close()
+
+	e, err := New(slave, io.Discard)
+	s.Require().NoError(err)
+
+	// raw mode before writing, so both lines are queued unchanged and delivered by a single read
+	state, err := makeRaw(slave.Fd())
+	s.Require().NoError(err)
+	defer restore(slave.Fd(), state)
+
+	_, err = master.Write([]byte("let x = 1;\rx + 1\r"))
+	s.Require().NoError(err)
+
+	// a lost line would block ReadLine, closing the terminal makes it return
+	timer := time.AfterFunc(5*time.Second, func() { master.Close() })
+	defer timer.Stop()
+
+	for _, expected := range []string{"let x = 1;", "x + 1"} {
+		line, err := e.ReadLine(">> ")
+		s.Require().NoError(err)
+		s.Require().Equal(expected, line)
+	}
+}
@@ -0,0 +1,228 @@
+package lineedit
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type Suite struct {
+	suite.Suite
+}
+
+func (s *Suite) SetupTest() {
+}
+
+func TestRunSuite(t *testing.T) {
+	suite.Run(t, new(Suite))
+}
+
+const (
+	up    = "\x1b[A"
+	down  = "\x1b[B"
+	right = "\x1b[C"
+	left  = "\x1b[D"
+	home  = "\x1b[H"
+	del   = "\x1b[3~"
+)
+
+func (s *Suite) TestEditing() {
+	tests := []struct {
+		keys     string
+		expected string
+	}{
+		{"hello\r", "hello"},
+		{"hello\n", "hello"},
+		{"helo" + left + "l\r", "hello"},
+		{"ello\x01h\r", "hello"},
+		{"hello" + home + del + "j\r", "jello"},
+		{"hellp\x7fo\r", "hello"},
+		{"hello world\x17\r", "hello "},
+		{"hello world\x01\x06\x06\x0b\r", "he"},
+		{"hello world" + left + left + "\x15\r", "ld"},
+		{"ab\x02\x02\x05c\r", "abc"},
+		{"größe" + left + "ß\r", "größße"},
+		{"x\x04\x01\x04y\r", "y"},
+	}
+
+	for _, tt := range tests {
+		e := &Editor{out: io.Discard}
+		line, err := e.readLine(">>", bufio.NewReader(strings.NewReader(tt.keys)))
+
+		s.Require().NoError(err, tt.keys)
+		s.Require().Equal(tt.expected, line, tt.keys)
+	}
+}
+
+func (s *Suite) TestControlKeys() {
+	e := &Editor{out: io.Discard}
+
+	_, err := e.readLine(">>", bufio.NewReader(strings.NewReader("\x04")))
+	s.Require().ErrorIs(err, io.EOF)
+
+	_, err = e.readLine(">>", bufio.NewReader(strings.NewReader("abc\x03")))
+	s.Require().ErrorIs(err, ErrInterrupted)
+
+	_, err = e.readLine(">>", bufio.NewReader(strings.NewReader("abc")))
+	s.Require().ErrorIs(err, io.EOF)
+}
+
+func (s *Suite) TestHistory() {
+	e := &Editor{out: io.Discard}
+	e.AddHistory("let a = 1")
+	e.AddHistory("let b = 2")
+	e.AddHistory("let b = 2")
+	e.AddHistory("  ")
+	e.AddHistory("a + b")
+
+	s.Require().Equal([]string{"let a = 1", "let b = 2", "a + b"}, e.History())
+
+	tests := []struct {
+		keys     string
+		expected string
+	}{
+		{up + "\r", "a + b"},
+		{up + up + "\r", "let b = 2"},
+		{up + up + up + up + up + "\r", "let a = 1"},
+		{"typed" + up + down + "\r", "typed"},
+		{up + up + down + "\r", "a + b"},
+		{"\x10\x10\x0e\r", "a + b"},
+	}
+
+	for _, tt := range tests {
+		line, err := e.readLine(">>", bufio.NewReader(strings.NewReader(tt.keys)))
+
+		s.Require().NoError(err, tt.keys)
+		s.Require().Equal(tt.expected, line, tt.keys)
+	}
+}
+
+func (s *Suite) TestReverseSearch() {
+	e := &Editor{out: io.Discard}
+	e.AddHistory("let add = fn(a, b) { a + b }")
+	e.AddHistory("let x = 1")
+	e.AddHistory("add(x, 2)")
+
+	tests := []struct {
+		keys     string
+		expected string
+	}{
+		{"\x12add\r", "add(x, 2)"},
+		{"\x12add\x12\r", "let add = fn(a, b) { a + b }"},
+		{"\x12let\x05;\r", "let x = 1;"},
+		{"\x12lex\x7f\r", "let x = 1"},
+		{"abc\x12x\x07d\r", "abcd"},
+		{"\x12nothing\r", ""},
+	}
+
+	for _, tt := range tests {
+		line, err := e.readLine(">>", bufio.NewReader(strings.NewReader(tt.keys)))
+
+		s.Require().NoError(err, tt.keys)
+		s.Require().Equal(tt.expected, line, tt.keys)
+	}
+}
+
+func (s *Suite) TestCompletion() {
+	words := []string{"let", "len", "last", "puts"}
+
+	var out strings.Builder
+	e := &Editor{out: &out}
+	e.Complete = func(line []rune, pos int) ([]string, int) {
+		start := pos
+		for start > 0 && line[start-1] != ' ' && line[start-1] != '(' {
+			start--
+		}
+		prefix := string(line[start:pos])
+
+		var candidates []string
+		for _, word := range words {
+			if strings.HasPrefix(word, prefix) {
+				candidates = append(candidates, word)
+			}
+		}
+		return candidates, start
+	}
+
+	tests := []struct {
+		keys     string
+		expected string
+	}{
+		{"pu\t\r", "puts "},
+		{"l\t\r", "l"},
+		{"le\t\r", "le"},
+		{"lef\t\r", "lef"},
+		{"x(la\t\r", "x(last "},
+		{"pu" + left + "\t\r", "puts u"},
+	}
+
+	for _, tt := range tests {
+		line, err := e.readLine(">>", bufio.NewReader(strings.NewReader(tt.keys)))
+
+		s.Require().NoError(err, tt.keys)
+		s.Require().Equal(tt.expected, line, tt.keys)
+	}
+
+	// ambiguous prefixes list the candidates
+	s.Require().Contains(out.String(), "let  len")
+}
+
+func (s *Suite) TestHistoryFile() {
+	path := filepath.Join(s.T().TempDir(), "history")
+	s.Require().NoError(os.WriteFile(path, []byte("one\ntwo\n"), 0o600))
+
+	e := &Editor{out: io.Discard}
+	s.Require().NoError(e.OpenHistory(path))
+	e.AddHistory("three")
+	s.Require().NoError(e.Close())
+
+	s.Require().Equal([]string{"one", "two", "three"}, e.History())
+
+	content, err := os.ReadFile(path)
+	s.Require().NoError(err)
+	s.Require().Equal("one\ntwo\nthree\n", string(content))
+}
+
+func (s *Suite) TestHistoryFileIsTruncated() {
+	path := filepath.Join(s.T().TempDir(), "history")
+
+	var content strings.Builder
+	for i := 0; i < MaxHistory+10; i++ {
+		fmt.Fprintf(&content, "entry %d\n", i)
+	}
+	s.Require().NoError(os.WriteFile(path, []byte(content.String()), 0o600))
+
+	e := &Editor{out: io.Discard}
+	s.Require().NoError(e.OpenHistory(path))
+	s.Require().Len(e.History(), MaxHistory)
+	s.Require().Equal("entry 10", e.History()[0])
+
+	e.AddHistory("new")
+	s.Require().NoError(e.Close())
+
+	data, err := os.ReadFile(path)
+	s.Require().NoError(err)
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	s.Require().Len(lines, MaxHistory+1)
+	s.Require().Equal("entry 10", lines[0])
+	s.Require().Equal("new", lines[MaxHistory])
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	s.Require().NoError(err)
+	s.Require().Len(entries, 1, "temporary file left behind")
+}
+
+func (s *Suite) TestNewRequiresTerminal() {
+	file, err := os.CreateTemp(s.T().TempDir(), "input")
+	s.Require().NoError(err)
+	defer file.Close()
+
+	_, err = New(file, io.Discard)
+	s.Require().Error(err)
+}
@@ -0,0 +1,25 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd
+
+package lineedit
+
+import (
+	"errors"
+	"os"
+)
+
+type terminalState struct{}
+
+// IsTerminal reports whether f is a character device, as terminal settings are not supported on this
+// platform
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func isTerminal(fd uintptr) bool { return false }
+
+func makeRaw(fd uintptr) (*terminalState, error) {
+	return nil, errors.New("line editing is not supported on this platform")
+}
+
+func restore(fd uintptr, state *terminalState) error { return nil }
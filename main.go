@@ -1,19 +1,154 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
 	"os/user"
 
+	"monkey/evaluator"
+	"monkey/lineedit"
+	"monkey/monkey"
+	"monkey/object"
 	"monkey/repl"
 )
 
+const usage = `usage:
+  monkey                        start the interactive REPL
+  monkey run <script> [args...] run a script file
+  monkey -e <source> [args...]  evaluate source code and print the result
+  monkey < script               run a script read from standard input
+
+options:
+  --no-color                    disable colored output in the REPL, also disabled by setting NO_COLOR
+`
+
+// exit codes
+const (
+	exitOK          = 0
+	exitRuntimeErr  = 1
+	exitSyntaxError = 2
+	exitUsage       = 64
+)
+
 func main() {
-	user, err := user.Current()
-	if err != nil {
-		panic(err)
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(arguments []string, stdin *os.File, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("monkey", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() { io.WriteString(stderr, usage) }
+	source := flags.String("e", "", "evaluate source code and print the result")
+	noColor := flags.Bool("no-color", false, "disable colored output in the REPL")
+
+	if err := flags.Parse(arguments); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	args := flags.Args()
+
+	switch {
+	case isFlagSet(flags, "e"):
+		interp, code := newInterpreter(args, stdout, stderr)
+		if interp == nil {
+			return code
+		}
+		result, err := interp.Run(*source)
+		if err != nil {
+			return reportError(stderr, err)
+		}
+		if result != evaluator.NULL {
+			fmt.Fprintln(stdout, result.Inspect())
+		}
+		return exitOK
+	case len(args) > 0 && args[0] == "run":
+		if len(args) < 2 {
+			io.WriteString(stderr, usage)
+			return exitUsage
+		}
+		interp, code := newInterpreter(args[2:], stdout, stderr)
+		if interp == nil {
+			return code
+		}
+		if _, err := interp.RunFile(args[1]); err != nil {
+			return reportError(stderr, err)
+		}
+		return exitOK
+	case len(args) > 0:
+		fmt.Fprintf(stderr, "unknown command %q\n", args[0])
+		io.WriteString(stderr, usage)
+		return exitUsage
+	case !lineedit.IsTerminal(stdin):
+		src, err := io.ReadAll(stdin)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitRuntimeErr
+		}
+		interp, code := newInterpreter(nil, stdout, stderr)
+		if interp == nil {
+			return code
+		}
+		if _, err := interp.Run(string(src)); err != nil {
+			return reportError(stderr, err)
+		}
+		return exitOK
+	default:
+		greet(stdout)
+		repl.StartWithOptions(stdin, stdout, repl.Options{Color: !*noColor && repl.ColorEnabled(stdout)})
+		return exitOK
+	}
+}
+
+// newInterpreter creates an interpreter with the script arguments bound to the args global
+func newInterpreter(args []string, stdout, stderr io.Writer) (*monkey.Interpreter, int) {
+	interp := monkey.New()
+	interp.SetOutput(stdout)
+
+	scriptArgs := make([]object.Object, len(args))
+	for i, arg := range args {
+		scriptArgs[i] = &object.String{Value: arg}
+	}
+	if err := interp.Set("args", &object.Array{Elements: scriptArgs}); err != nil {
+		fmt.Fprintln(stderr, err)
+		return nil, exitRuntimeErr
+	}
+
+	return interp, exitOK
+}
+
+// reportError prints the error and returns the matching exit code
+func reportError(stderr io.Writer, err error) int {
+	var syntaxErr *monkey.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		io.WriteString(stderr, syntaxErr.Render())
+		return exitSyntaxError
+	}
+
+	fmt.Fprintf(stderr, "error: %s\n", err)
+	return exitRuntimeErr
+}
+
+func isFlagSet(flags *flag.FlagSet, name string) bool {
+	set := false
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+func greet(out io.Writer) {
+	name := "there"
+	if u, err := user.Current(); err == nil {
+		name = u.Username
 	}
-	fmt.Printf("Hello %s! This is the monkey programming language\n", user.Username)
-	fmt.Printf("Feel free to type in commands\n")
-	repl.Start(os.Stdin, os.Stdout)
+	fmt.Fprintf(out, "Hello %s! This is the monkey programming language\n", name)
+	fmt.Fprintf(out, "Feel free to type in commands\n")
 }
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type Suite struct {
+	suite.Suite
+}
+
+func (s *Suite) SetupTest() {
+}
+
+func TestRunSuite(t *testing.T) {
+	suite.Run(t, new(Suite))
+}
+
+func (s *Suite) TestRun() {
+	dir := s.T().TempDir()
+	script := filepath.Join(dir, "script.mk")
+	s.Require().NoError(os.WriteFile(script, []byte(`puts(len(args), first(args))`), 0o644))
+	broken := filepath.Join(dir, "broken.mk")
+	s.Require().NoError(os.WriteFile(broken, []byte("let x = 1;\nlet = 2;\n"), 0o644))
+
+	tests := []struct {
+		args           []string
+		stdin          string
+		expectedCode   int
+		expectedStdout string
+		expectedStderr string
+	}{
+		{[]string{"run", script, "a", "b"}, "", exitOK, "2\na\n", ""},
+		{[]string{"run", broken}, "", exitSyntaxError, "", broken + ":2:5: expected next token to be IDENT, got = instead\n   2 | let = 2;\n     |     ^\n"},
+		{[]string{"-e", "1 + 2"}, "", exitOK, "3\n", ""},
+		{[]string{"-e", "args[1]", "x", "y"}, "", exitOK, "y\n", ""},
+		{[]string{"-e", "let x = 1;"}, "", exitOK, "", ""},
+		{[]string{"-e", "[if (true) {}]"}, "", exitOK, "[null]\n", ""},
+		{[]string{"-e", "1 + true"}, "", exitRuntimeErr, "", "error: type mismatch: INTEGER + BOOLEAN\n"},
+		{nil, `puts("piped")`, exitOK, "piped\n", ""},
+		{nil, `-true`, exitRuntimeErr, "", "error: unknown operator: -BOOLEAN\n"},
+		{[]string{"run"}, "", exitUsage, "", usage},
+	}
+
+	for _, tt := range tests {
+		stdin := filepath.Join(dir, "stdin")
+		s.Require().NoError(os.WriteFile(stdin, []byte(tt.stdin), 0o644))
+		in, err := os.Open(stdin)
+		s.Require().NoError(err)
+
+		var stdout, stderr bytes.Buffer
+		code := run(tt.args, in, &stdout, &stderr)
+		in.Close()
+
+		s.Require().Equal(tt.expectedCode, code, tt.args)
+		s.Require().Equal(tt.expectedStdout, stdout.String(), tt.args)
+		s.Require().Equal(tt.expectedStderr, stderr.String(), tt.args)
+	}
+}
+
+func (s *Suite) TestRunWithCharacterDeviceInput() {
+	// /dev/null is a character device but not a terminal, so it is read like piped input
+	in, err := os.Open(os.DevNull)
+	s.Require().NoError(err)
+	defer in.Close()
+
+	var stdout, stderr bytes.Buffer
+	s.Require().Equal(exitOK, run(nil, in, &stdout, &stderr))
+	s.Require().Empty(stdout.String())
+	s.Require().Empty(stderr.String())
+}
@@ -0,0 +1,468 @@
+package monkey
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+
+	"monkey/evaluator"
+	"monkey/object"
+)
+
+var (
+	objectType = reflect.TypeOf((*object.Object)(nil)).Elem()
+	errorType  = reflect.TypeOf((*error)(nil)).Elem()
+	bigIntType = reflect.TypeOf((*big.Int)(nil))
+)
+
+// ToObject converts a Go value into a Monkey object. Integers, floats and booleans, strings, slices and arrays,
+// maps, structs and pointers to any of these are supported, *big.Int becomes an integer or a big integer,
+// values that already are objects are returned unchanged and nil becomes null.
+func ToObject(value any) (object.Object, error) {
+	if value == nil {
+		return evaluator.NULL, nil
+	}
+	return toObject(reflect.ValueOf(value), references{})
+}
+
+// reference identifies the memory referred to by a pointer, map or slice
+type reference struct {
+	ptr    uintptr
+	typ    reflect.Type
+	length int // slices with the same pointer and type are different values if their lengths differ
+}
+
+// references holds the Go values which are being converted, to detect values containing themselves
+type references map[reference]bool
+
+// enter marks the pointer, map or slice as being converted, it fails if it already is, the caller must
+// leave it once its elements are converted
+func (r references) enter(v reflect.Value) (reference, error) {
+	ref := reference{ptr: v.Pointer(), typ: v.Type()}
+	if v.Kind() == reflect.Slice {
+		ref.length = v.Len()
+	}
+	if r[ref] {
+		return ref, fmt.Errorf("cannot convert %s containing itself", v.Type())
+	}
+	r[ref] = true
+	return ref, nil
+}
+
+func (r references) leave(ref reference) { delete(r, ref) }
+
+func toObject(v reflect.Value, seen references) (object.Object, error) {
+	if v.Type().Implements(objectType) {
+		if isNil(v) {
+			return evaluator.NULL, nil
+		}
+		return v.Interface().(object.Object), nil
+	}
+	if v.Type() == bigIntType {
+		if v.IsNil() {
+			return evaluator.NULL, nil
+		}
+		value := v.Interface().(*big.Int)
+		if value.IsInt64() {
+			return &object.Integer{Value: value.Int64()}, nil
+		}
+		return &object.BigInt{Value: new(big.Int).Set(value)}, nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			return evaluator.TRUE, nil
+		}
+		return evaluator.FALSE, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &object.Integer{Value: v.Int()}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		value := v.Uint()
+		if value > 1<<63-1 {
+			return &object.BigInt{Value: new(big.Int).SetUint64(value)}, nil
+		}
+		return &object.Integer{Value: int64(value)}, nil
+	case reflect.Float32, reflect.Float64:
+		return &object.Float{Value: v.Float()}, nil
+	case reflect.String:
+		return &object.String{Value: v.String()}, nil
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice {
+			if v.IsNil() {
+				return evaluator.NULL, nil
+			}
+			ref, err := seen.enter(v)
+			if err != nil {
+				return nil, err
+			}
+			defer seen.leave(ref)
+		}
+		elements := make([]object.Object, v.Len())
+		for i := range elements {
+			elem, err := toObject(v.Index(i), seen)
+			if err != nil {
+				return nil, fmt.Errorf("index %d: %w", i, err)
+			}
+			elements[i] = elem
+		}
+		return &object.Array{Elements: elements}, nil
+	case reflect.Map:
+		if v.IsNil() {
+			return evaluator.NULL, nil
+		}
+		ref, err := seen.enter(v)
+		if err != nil {
+			return nil, err
+		}
+		defer seen.leave(ref)
+		// Go maps are unordered, sort the keys so the resulting hash is deterministic
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		hash := object.NewHash()
+		for _, k := range keys {
+			key, err := toObject(k, seen)
+			if err != nil {
+				return nil, err
+			}
+			hashable, ok := key.(object.Hashable)
+			if !ok {
+				return nil, fmt.Errorf("unusable as hash key: %s", key.Type())
+			}
+			value, err := toObject(v.MapIndex(k), seen)
+			if err != nil {
+				return nil, fmt.Errorf("key %v: %w", k.Interface(), err)
+			}
+			hash.Set(hashable.HashKey(), object.HashPair{Key: key, Value: value})
+		}
+		return hash, nil
+	case reflect.Struct:
+		hash := object.NewHash()
+		for _, field := range reflect.VisibleFields(v.Type()) {
+			name, ok := fieldName(field)
+			if !ok {
+				continue
+			}
+			value, err := toObject(v.FieldByIndex(field.Index), seen)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", field.Name, err)
+			}
+			key := &object.String{Value: name}
+			hash.Set(key.HashKey(), object.HashPair{Key: key, Value: value})
+		}
+		return hash, nil
+	case reflect.Pointer:
+		if v.IsNil() {
+			return evaluator.NULL, nil
+		}
+		ref, err := seen.enter(v)
+		if err != nil {
+			return nil, err
+		}
+		defer seen.leave(ref)
+		return toObject(v.Elem(), seen)
+	case reflect.Interface:
+		if v.IsNil() {
+			return evaluator.NULL, nil
+		}
+		return toObject(v.Elem(), seen)
+	default:
+		return nil, fmt.Errorf("unsupported Go type %s", v.Type())
+	}
+}
+
+// isNil reports whether v is a nil pointer, interface, map, slice, function or channel, other kinds of
+// values cannot be nil
+func isNil(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		return v.IsNil()
+	}
+	return false
+}
+
+// FromObject converts a Monkey object into a Go value of the given type. Converting into an interface
+// type yields int64, *big.Int, float64, string, bool, nil, []any or map[any]any depending on the object.
+// Integers may be converted into float types and *big.Int, floats are not truncated into integer types.
+func FromObject(obj object.Object, typ reflect.Type) (reflect.Value, error) {
+	return convertObject(obj, typ, visiting{})
+}
+
+// visiting holds the arrays and hashes which are being converted, to detect values containing themselves
+type visiting map[object.Object]bool
+
+// enter marks the array or hash as being converted, it fails if it already is, the caller must leave it
+// once its elements are converted
+func (v visiting) enter(obj object.Object) error {
+	if v[obj] {
+		return fmt.Errorf("cannot convert %s containing itself", obj.Type())
+	}
+	v[obj] = true
+	return nil
+}
+
+func (v visiting) leave(obj object.Object) { delete(v, obj) }
+
+func convertObject(obj object.Object, typ reflect.Type, seen visiting) (reflect.Value, error) {
+	if typ.Kind() == reflect.Interface && objectType.Implements(typ) && typ.NumMethod() > 0 {
+		// the target is object.Object or an interface satisfied by every object
+		if !reflect.TypeOf(obj).Implements(typ) {
+			return reflect.Value{}, fmt.Errorf("cannot use %s as %s", obj.Type(), typ)
+		}
+		return reflect.ValueOf(obj), nil
+	}
+	if reflect.TypeOf(obj).AssignableTo(typ) && typ.Kind() != reflect.Interface {
+		// the target is a concrete object type, e.g. *object.Hash
+		return reflect.ValueOf(obj), nil
+	}
+
+	if _, ok := obj.(*object.Null); ok {
+		switch typ.Kind() {
+		case reflect.Interface, reflect.Pointer, reflect.Slice, reflect.Map:
+			return reflect.Zero(typ), nil
+		}
+	}
+
+	if typ == bigIntType {
+		switch number := obj.(type) {
+		case *object.Integer:
+			return reflect.ValueOf(big.NewInt(number.Value)), nil
+		case *object.BigInt:
+			return reflect.ValueOf(new(big.Int).Set(number.Value)), nil
+		default:
+			return reflect.Value{}, typeMismatch(obj, typ)
+		}
+	}
+
+	switch typ.Kind() {
+	case reflect.Interface:
+		value, err := fromObject(obj, seen)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if value == nil {
+			return reflect.Zero(typ), nil
+		}
+		v := reflect.ValueOf(value)
+		if !v.Type().AssignableTo(typ) {
+			return reflect.Value{}, fmt.Errorf("cannot use %s as %s", obj.Type(), typ)
+		}
+		return v, nil
+	case reflect.Bool:
+		boolean, ok := obj.(*object.Boolean)
+		if !ok {
+			return reflect.Value{}, typeMismatch(obj, typ)
+		}
+		return reflect.ValueOf(boolean.Value).Convert(typ), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if number, ok := obj.(*object.BigInt); ok {
+			return reflect.Value{}, fmt.Errorf("%s overflows %s", number.Value, typ)
+		}
+		integer, ok := obj.(*object.Integer)
+		if !ok {
+			return reflect.Value{}, typeMismatch(obj, typ)
+		}
+		v := reflect.New(typ).Elem()
+		if v.OverflowInt(integer.Value) {
+			return reflect.Value{}, fmt.Errorf("%d overflows %s", integer.Value, typ)
+		}
+		v.SetInt(integer.Value)
+		return v, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if number, ok := obj.(*object.BigInt); ok {
+			// big integers between 2^63 and 2^64 still fit in a uint64
+			v := reflect.New(typ).Elem()
+			if !number.Value.IsUint64() || v.OverflowUint(number.Value.Uint64()) {
+				return reflect.Value{}, fmt.Errorf("%s overflows %s", number.Value, typ)
+			}
+			v.SetUint(number.Value.Uint64())
+			return v, nil
+		}
+		integer, ok := obj.(*object.Integer)
+		if !ok {
+			return reflect.Value{}, typeMismatch(obj, typ)
+		}
+		v := reflect.New(typ).Elem()
+		if integer.Value < 0 || v.OverflowUint(uint64(integer.Value)) {
+			return reflect.Value{}, fmt.Errorf("%d overflows %s", integer.Value, typ)
+		}
+		v.SetUint(uint64(integer.Value))
+		return v, nil
+	case reflect.Float32, reflect.Float64:
+		var value float64
+		switch number := obj.(type) {
+		case *object.Float:
+			value = number.Value
+		case *object.Integer:
+			value = float64(number.Value)
+		case *object.BigInt:
+			value, _ = new(big.Float).SetInt(number.Value).Float64()
+		default:
+			return reflect.Value{}, typeMismatch(obj, typ)
+		}
+		v := reflect.New(typ).Elem()
+		if v.OverflowFloat(value) {
+			return reflect.Value{}, fmt.Errorf("%s overflows %s", obj.Inspect(), typ)
+		}
+		v.SetFloat(value)
+		return v, nil
+	case reflect.String:
+		str, ok := obj.(*object.String)
+		if !ok {
+			return reflect.Value{}, typeMismatch(obj, typ)
+		}
+		return reflect.ValueOf(str.Value).Convert(typ), nil
+	case reflect.Slice:
+		array, ok := obj.(*object.Array)
+		if !ok {
+			return reflect.Value{}, typeMismatch(obj, typ)
+		}
+		if err := seen.enter(array); err != nil {
+			return reflect.Value{}, err
+		}
+		defer seen.leave(array)
+		v := reflect.MakeSlice(typ, len(array.Elements), len(array.Elements))
+		for i, elem := range array.Elements {
+			converted, err := convertObject(elem, typ.Elem(), seen)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("index %d: %w", i, err)
+			}
+			v.Index(i).Set(converted)
+		}
+		return v, nil
+	case reflect.Map:
+		hash, ok := obj.(*object.Hash)
+		if !ok {
+			return reflect.Value{}, typeMismatch(obj, typ)
+		}
+		if err := seen.enter(hash); err != nil {
+			return reflect.Value{}, err
+		}
+		defer seen.leave(hash)
+		v := reflect.MakeMapWithSize(typ, hash.Len())
+		for _, pair := range hash.Pairs() {
+			key, err := convertObject(pair.Key, typ.Key(), seen)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("key %s: %w", pair.Key.Inspect(), err)
+			}
+			value, err := convertObject(pair.Value, typ.Elem(), seen)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("key %s: %w", pair.Key.Inspect(), err)
+			}
+			v.SetMapIndex(key, value)
+		}
+		return v, nil
+	case reflect.Struct:
+		hash, ok := obj.(*object.Hash)
+		if !ok {
+			return reflect.Value{}, typeMismatch(obj, typ)
+		}
+		if err := seen.enter(hash); err != nil {
+			return reflect.Value{}, err
+		}
+		defer seen.leave(hash)
+		v := reflect.New(typ).Elem()
+		for _, field := range reflect.VisibleFields(typ) {
+			name, ok := fieldName(field)
+			if !ok {
+				continue
+			}
+			pair, ok := hash.Get((&object.String{Value: name}).HashKey())
+			if !ok {
+				continue
+			}
+			value, err := convertObject(pair.Value, field.Type, seen)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("field %s: %w", field.Name, err)
+			}
+			v.FieldByIndex(field.Index).Set(value)
+		}
+		return v, nil
+	case reflect.Pointer:
+		elem, err := convertObject(obj, typ.Elem(), seen)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		v := reflect.New(typ.Elem())
+		v.Elem().Set(elem)
+		return v, nil
+	default:
+		return reflect.Value{}, fmt.Errorf("unsupported Go type %s", typ)
+	}
+}
+
+// fromObject converts an object into its natural Go representation
+func fromObject(obj object.Object, seen visiting) (any, error) {
+	switch obj := obj.(type) {
+	case *object.Null:
+		return nil, nil
+	case *object.Boolean:
+		return obj.Value, nil
+	case *object.Integer:
+		return obj.Value, nil
+	case *object.BigInt:
+		return new(big.Int).Set(obj.Value), nil
+	case *object.Float:
+		return obj.Value, nil
+	case *object.String:
+		return obj.Value, nil
+	case *object.Array:
+		if err := seen.enter(obj); err != nil {
+			return nil, err
+		}
+		defer seen.leave(obj)
+		elements := make([]any, len(obj.Elements))
+		for i, elem := range obj.Elements {
+			value, err := fromObject(elem, seen)
+			if err != nil {
+				return nil, fmt.Errorf("index %d: %w", i, err)
+			}
+			elements[i] = value
+		}
+		return elements, nil
+	case *object.Hash:
+		if err := seen.enter(obj); err != nil {
+			return nil, err
+		}
+		defer seen.leave(obj)
+		m := make(map[any]any, obj.Len())
+		for _, pair := range obj.Pairs() {
+			key, err := fromObject(pair.Key, seen)
+			if err != nil {
+				return nil, err
+			}
+			value, err := fromObject(pair.Value, seen)
+			if err != nil {
+				return nil, fmt.Errorf("key %s: %w", pair.Key.Inspect(), err)
+			}
+			m[key] = value
+		}
+		return m, nil
+	default:
+		// functions and other objects have no Go equivalent and are passed as they are
+		return obj, nil
+	}
+}
+
+// fieldName returns the hash key used for a struct field, the name can be changed with a `monkey:"name"`
+// tag and the field skipped with `monkey:"-"`
+func fieldName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() || field.Anonymous {
+		return "", false
+	}
+	switch tag := field.Tag.Get("monkey"); tag {
+	case "-":
+		return "", false
+	case "":
+		return field.Name, true
+	default:
+		return tag, true
+	}
+}
+
+func typeMismatch(obj object.Object, typ reflect.Type) error {
+	return fmt.Errorf("cannot use %s as %s", obj.Type(), typ)
+}
@@ -0,0 +1,36 @@
+package monkey
+
+import (
+	"bytes"
+	"strings"
+
+	"monkey/parser"
+)
+
+// SyntaxError is returned when the source code could not be parsed
+type SyntaxError struct {
+	Source string
+	Errors []*parser.ParseError
+}
+
+func (e *SyntaxError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Render returns the errors together with the offending source lines, see parser.RenderErrors
+func (e *SyntaxError) Render() string {
+	var out bytes.Buffer
+	parser.RenderErrors(&out, e.Source, e.Errors)
+	return out.String()
+}
+
+// RuntimeError is returned when evaluation ends in an error object
+type RuntimeError struct {
+	Message string
+}
+
+func (e *RuntimeError) Error() string { return e.Message }
@@ -0,0 +1,219 @@
+// Package monkey embeds the Monkey interpreter into Go programs
+package monkey
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+
+	"monkey/evaluator"
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/parser"
+)
+
+// Interpreter holds the global environment scripts are evaluated in
+type Interpreter struct {
+	env *object.Environment
+}
+
+func New() *Interpreter {
+	return &Interpreter{env: object.NewEnvironment()}
+}
+
+// SetIntegerOverflow selects whether integer results that do not fit in 64 bits become big integers,
+// which is the default, or runtime errors
+func (i *Interpreter) SetIntegerOverflow(overflow object.IntegerOverflow) {
+	i.env.SetIntegerOverflow(overflow)
+}
+
+// SetOutput changes where builtins like puts write, which is os.Stdout by default
+func (i *Interpreter) SetOutput(w io.Writer) {
+	i.env.SetOutput(w)
+}
+
+// Environment returns the global environment of the interpreter
+func (i *Interpreter) Environment() *object.Environment {
+	return i.env
+}
+
+// Run evaluates the source code in the global environment and returns the value of the last statement
+func (i *Interpreter) Run(src string) (object.Object, error) {
+	return i.run(lexer.New(src), src)
+}
+
+// RunFile evaluates the script at the given path, positions in errors refer to the path
+func (i *Interpreter) RunFile(path string) (object.Object, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return i.run(lexer.NewWithFilename(path, string(src)), string(src))
+}
+
+func (i *Interpreter) run(lex *lexer.Lexer, src string) (object.Object, error) {
+	p := parser.New(lex)
+	program := p.ParseProgram()
+	if len(p.ParseErrors()) != 0 {
+		return nil, &SyntaxError{Source: src, Errors: p.ParseErrors()}
+	}
+
+	return result(evaluator.Eval(program, i.env))
+}
+
+// Call invokes the Monkey function or builtin bound to the given global name, the arguments are
+// converted with ToObject
+func (i *Interpreter) Call(name string, args ...any) (object.Object, error) {
+	fn, ok := i.env.Get(name)
+	if !ok {
+		builtin, ok := evaluator.LookupBuiltin(name)
+		if !ok {
+			return nil, fmt.Errorf("identifier not found: %s", name)
+		}
+		fn = builtin
+	}
+
+	switch fn.(type) {
+	case *object.Function, *object.Builtin:
+	default:
+		return nil, fmt.Errorf("not a function: %s", fn.Type())
+	}
+
+	objects := make([]object.Object, len(args))
+	for idx, arg := range args {
+		obj, err := ToObject(arg)
+		if err != nil {
+			return nil, fmt.Errorf("argument %d to `%s`: %w", idx+1, name, err)
+		}
+		objects[idx] = obj
+	}
+
+	return result(evaluator.ApplyFunction(fn, objects, i.env))
+}
+
+// Get returns the value bound to the given global name
+func (i *Interpreter) Get(name string) (object.Object, bool) {
+	return i.env.Get(name)
+}
+
+// Set binds a Go value, converted with ToObject, to the given global name. It fails with an error
+// wrapping object.ErrConstant if the script declared the name as a constant.
+func (i *Interpreter) Set(name string, value any) error {
+	obj, err := ToObject(value)
+	if err != nil {
+		return fmt.Errorf("cannot set %s: %w", name, err)
+	}
+	if !i.env.Declare(name, obj, false) {
+		return fmt.Errorf("cannot set %s: %w", name, object.ErrConstant)
+	}
+	return nil
+}
+
+// result turns error objects into Go errors, statements without a value evaluate to null
+func result(obj object.Object) (object.Object, error) {
+	switch obj := obj.(type) {
+	case nil:
+		return evaluator.NULL, nil
+	case *object.Error:
+		return nil, &RuntimeError{Message: obj.Message}
+	default:
+		return obj, nil
+	}
+}
+
+// Register binds a Go function to the given global name. Arguments and results are converted with
+// FromObject and ToObject, a non-nil error returned as the last result becomes an error object.
+// Functions with the signature of object.BuiltinFunction are registered without any conversion. Like Set,
+// it fails if the script declared the name as a constant.
+func (i *Interpreter) Register(name string, fn any) error {
+	builtin, err := wrapFunction(name, fn)
+	if err != nil {
+		return err
+	}
+	if !i.env.Declare(name, builtin, false) {
+		return fmt.Errorf("cannot register %s: %w", name, object.ErrConstant)
+	}
+	return nil
+}
+
+func wrapFunction(name string, fn any) (*object.Builtin, error) {
+	switch fn := fn.(type) {
+	case object.BuiltinFunction:
+		return &object.Builtin{Name: name, Fn: fn}, nil
+	case func(args ...object.Object) object.Object:
+		return &object.Builtin{Name: name, Fn: fn}, nil
+	}
+
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return nil, fmt.Errorf("cannot register %s: %T is not a function", name, fn)
+	}
+
+	typ := v.Type()
+	returnsError := typ.NumOut() > 0 && typ.Out(typ.NumOut()-1) == errorType
+	results := typ.NumOut()
+	if returnsError {
+		results -= 1
+	}
+	if results > 1 {
+		return nil, fmt.Errorf("cannot register %s: functions may return at most one value and an error", name)
+	}
+
+	call := func(args ...object.Object) object.Object {
+		in, err := convertArguments(name, typ, args)
+		if err != nil {
+			return &object.Error{Message: err.Error()}
+		}
+
+		out := v.Call(in)
+
+		if returnsError {
+			if err, _ := out[len(out)-1].Interface().(error); err != nil {
+				return &object.Error{Message: err.Error()}
+			}
+		}
+		if results == 0 {
+			return nil
+		}
+
+		result, err := toObject(out[0], references{})
+		if err != nil {
+			return &object.Error{Message: fmt.Sprintf("result of `%s`: %s", name, err)}
+		}
+		return result
+	}
+
+	return &object.Builtin{Name: name, Fn: call}, nil
+}
+
+// convertArguments converts the Monkey arguments into the parameter types of the Go function
+func convertArguments(name string, typ reflect.Type, args []object.Object) ([]reflect.Value, error) {
+	params := typ.NumIn()
+
+	if typ.IsVariadic() {
+		if len(args) < params-1 {
+			return nil, fmt.Errorf("wrong number of arguments: want at least %d, got=%d", params-1, len(args))
+		}
+	} else if len(args) != params {
+		return nil, fmt.Errorf("wrong number of arguments: want=%d, got=%d", params, len(args))
+	}
+
+	in := make([]reflect.Value, len(args))
+	for idx, arg := range args {
+		var paramType reflect.Type
+		if typ.IsVariadic() && idx >= params-1 {
+			paramType = typ.In(params - 1).Elem()
+		} else {
+			paramType = typ.In(idx)
+		}
+
+		value, err := FromObject(arg, paramType)
+		if err != nil {
+			return nil, fmt.Errorf("argument %d to `%s`: %s", idx+1, name, err)
+		}
+		in[idx] = value
+	}
+
+	return in, nil
+}
@@ -0,0 +1,413 @@
+package monkey_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"monkey/evaluator"
+	"monkey/lexer"
+	"monkey/monkey"
+	"monkey/object"
+	"monkey/parser"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type Suite struct {
+	suite.Suite
+}
+
+func (s *Suite) SetupTest() {
+}
+
+func TestRunSuite(t *testing.T) {
+	suite.Run(t, new(Suite))
+}
+
+type user struct {
+	Name  string
+	Age   int    `monkey:"age"`
+	Token string `monkey:"-"`
+}
+
+func (s *Suite) TestRegister() {
+	interp := monkey.New()
+
+	s.Require().NoError(interp.Register("add", func(a, b int) int { return a + b }))
+	s.Require().NoError(interp.Register("upper", strings.ToUpper))
+	s.Require().NoError(interp.Register("join", func(sep string, parts ...string) string {
+		return strings.Join(parts, sep)
+	}))
+	s.Require().NoError(interp.Register("sum", func(xs []int64) int64 {
+		var total int64
+		for _, x := range xs {
+			total += x
+		}
+		return total
+	}))
+	s.Require().NoError(interp.Register("flag", func(name string) (bool, error) {
+		if name == "" {
+			return false, errors.New("empty flag name")
+		}
+		return name == "beta", nil
+	}))
+	s.Require().NoError(interp.Register("lookup", func(id int) *user {
+		if id != 1 {
+			return nil
+		}
+		return &user{Name: "Ada", Age: 36, Token: "secret"}
+	}))
+	s.Require().NoError(interp.Register("greet", func(u user) string { return "hi " + u.Name }))
+	s.Require().NoError(interp.Register("keys", func(m map[string]int) int { return len(m) }))
+	s.Require().NoError(interp.Register("describe", func(v any) string {
+		switch v.(type) {
+		case int64:
+			return "int"
+		case []any:
+			return "list"
+		case map[any]any:
+			return "map"
+		case nil:
+			return "nil"
+		default:
+			return "other"
+		}
+	}))
+	s.Require().NoError(interp.Register("raw", func(args ...object.Object) object.Object {
+		return &object.Integer{Value: int64(len(args))}
+	}))
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`add(1, 2)`, "3"},
+		{`upper("monkey")`, "MONKEY"},
+		{`join("-", "a", "b", "c")`, "a-b-c"},
+		{`join(",")`, ""},
+		{`sum([1, 2, 3])`, "6"},
+		{`flag("beta")`, "true"},
+		{`flag("")`, "ERROR: empty flag name"},
+		{`lookup(1)`, `{"Name": Ada, "age": 36}`},
+		{`lookup(2)`, "null"},
+		{`greet({"Name": "Bob"})`, "hi Bob"},
+		{`keys({"a": 1, "b": 2})`, "2"},
+		{`describe(1)`, "int"},
+		{`describe([1])`, "list"},
+		{`describe({})`, "map"},
+		{`describe(if (false) { 1 })`, "nil"},
+		{`raw(1, 2, 3)`, "3"},
+		{`add(1)`, "ERROR: wrong number of arguments: want=2, got=1"},
+		{`add(1, "2")`, "ERROR: argument 2 to `add`: cannot use STRING as int"},
+		{`sum([1, true])`, "ERROR: argument 1 to `sum`: index 1: cannot use BOOLEAN as int64"},
+	}
+
+	for _, tt := range tests {
+		evaluated := eval(interp, tt.input)
+		s.Require().Equal(tt.expected, evaluated.Inspect(), tt.input)
+	}
+}
+
+// nested is a recursive Go type that arrays containing themselves could be converted into
+type nested []nested
+
+func (s *Suite) TestCyclicValues() {
+	interp := monkey.New()
+
+	s.Require().NoError(interp.Register("convert", func(v any) string { return "ok" }))
+	s.Require().NoError(interp.Register("count", func(v nested) int { return len(v) }))
+	s.Require().NoError(interp.Register("size", func(m map[string]map[string]int) int { return len(m) }))
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`let a = [1]; a[0] = a; a`, "[[...]]"},
+		{`let h = {}; h["self"] = h; h["other"] = [h]; h`, `{"self": {...}, "other": [{...}]}`},
+		{`let a = [1]; a[0] = a; convert(a)`, "ERROR: argument 1 to `convert`: index 0: cannot convert ARRAY containing itself"},
+		{`let a = [1]; a[0] = a; count(a)`, "ERROR: argument 1 to `count`: index 0: cannot convert ARRAY containing itself"},
+		{`let h = {}; h["x"] = h; size(h)`, "ERROR: argument 1 to `size`: key x: cannot convert HASH containing itself"},
+		{`let b = [1]; convert([b, b])`, "ok"},
+		{`count([[], [[]]])`, "2"},
+	}
+
+	for _, tt := range tests {
+		evaluated := eval(interp, tt.input)
+		s.Require().Equal(tt.expected, evaluated.Inspect(), tt.input)
+	}
+}
+
+func (s *Suite) TestRegisterRejectsNonFunctions() {
+	interp := monkey.New()
+
+	s.Require().EqualError(interp.Register("x", 42), "cannot register x: int is not a function")
+	s.Require().EqualError(
+		interp.Register("pair", func() (int, int) { return 1, 2 }),
+		"cannot register pair: functions may return at most one value and an error",
+	)
+}
+
+func (s *Suite) TestIntegerOverflow() {
+	interp := monkey.New()
+	s.Require().NoError(interp.Register("small", func(x int8) int8 { return x }))
+
+	evaluated := eval(interp, `small(300)`)
+	s.Require().Equal("ERROR: argument 1 to `small`: 300 overflows int8", evaluated.Inspect())
+}
+
+func (s *Suite) TestIntegerOverflowModes() {
+	interp := monkey.New()
+	s.Require().NoError(interp.Register("double", func(x *big.Int) *big.Int { return new(big.Int).Lsh(x, 1) }))
+	s.Require().NoError(interp.Register("wide", func(x uint64) uint64 { return x }))
+
+	result, err := interp.Run(`9223372036854775807 + 1`)
+	s.Require().NoError(err)
+	s.Require().Equal("9223372036854775808", result.Inspect())
+
+	result, err = interp.Run(`double(4611686018427387904)`)
+	s.Require().NoError(err)
+	s.Require().Equal("9223372036854775808", result.Inspect())
+
+	result, err = interp.Run(`wide(9223372036854775807 + 1)`)
+	s.Require().NoError(err)
+	s.Require().Equal("9223372036854775808", result.Inspect())
+
+	interp.SetIntegerOverflow(object.ErrorOnOverflow)
+	_, err = interp.Run(`let f = fn(x) { x + 1 }; f(9223372036854775807)`)
+	s.Require().EqualError(err, "integer overflow: 9223372036854775807 + 1")
+
+	// small results of big.Int functions are plain integers
+	result, err = interp.Run(`double(2)`)
+	s.Require().NoError(err)
+	s.Require().Equal(object.ObjectType(object.INTEGER_OBJ), result.Type())
+}
+
+func (s *Suite) TestFloats() {
+	interp := monkey.New()
+	s.Require().NoError(interp.Register("percent", func(part, total float64) float64 { return part / total * 100 }))
+	s.Require().NoError(interp.Register("half", func(x float32) float32 { return x / 2 }))
+	s.Require().NoError(interp.Register("count", func(n int) int { return n }))
+	s.Require().NoError(interp.Set("ratio", 0.25))
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`percent(1, 4)`, "25.0"},
+		{`percent(0.5, 2)`, "25.0"},
+		{`half(3)`, "1.5"},
+		{`ratio * 2`, "0.5"},
+		{`count(1.5)`, "ERROR: argument 1 to `count`: cannot use FLOAT as int"},
+	}
+
+	for _, tt := range tests {
+		evaluated := eval(interp, tt.input)
+		s.Require().Equal(tt.expected, evaluated.Inspect(), tt.input)
+	}
+
+	value, err := monkey.FromObject(&object.Float{Value: 1.5}, reflect.TypeOf((*any)(nil)).Elem())
+	s.Require().NoError(err)
+	s.Require().Equal(1.5, value.Interface())
+}
+
+func eval(interp *monkey.Interpreter, input string) object.Object {
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	return evaluator.Eval(program, interp.Environment())
+}
+
+func (s *Suite) TestRun() {
+	interp := monkey.New()
+
+	result, err := interp.Run(`let double = fn(x) { x * 2 }; double(21)`)
+	s.Require().NoError(err)
+	s.Require().Equal("42", result.Inspect())
+
+	// the global environment is kept between runs
+	result, err = interp.Run(`double(5)`)
+	s.Require().NoError(err)
+	s.Require().Equal("10", result.Inspect())
+
+	result, err = interp.Run(`let x = 1;`)
+	s.Require().NoError(err)
+	s.Require().Equal(evaluator.NULL, result)
+}
+
+func (s *Suite) TestRunErrors() {
+	interp := monkey.New()
+
+	_, err := interp.Run(`let x = ;`)
+	var syntaxErr *monkey.SyntaxError
+	s.Require().ErrorAs(err, &syntaxErr)
+	s.Require().Equal("1:9: no prefix parse function for ; found", err.Error())
+	s.Require().Equal("1:9: no prefix parse function for ; found\n   1 | let x = ;\n     |         ^\n", syntaxErr.Render())
+
+	_, err = interp.Run(`1 + true`)
+	var runtimeErr *monkey.RuntimeError
+	s.Require().ErrorAs(err, &runtimeErr)
+	s.Require().Equal("type mismatch: INTEGER + BOOLEAN", err.Error())
+}
+
+func (s *Suite) TestRunFile() {
+	path := filepath.Join(s.T().TempDir(), "script.mk")
+	s.Require().NoError(os.WriteFile(path, []byte("let a = 1;\nlet b = ;\n"), 0o644))
+
+	_, err := monkey.New().RunFile(path)
+	s.Require().EqualError(err, path+":2:9: no prefix parse function for ; found")
+
+	s.Require().NoError(os.WriteFile(path, []byte("let a = [1, 2, 3];\nlen(a)\n"), 0o644))
+
+	result, err := monkey.New().RunFile(path)
+	s.Require().NoError(err)
+	s.Require().Equal("3", result.Inspect())
+
+	_, err = monkey.New().RunFile(filepath.Join(s.T().TempDir(), "missing.mk"))
+	s.Require().ErrorIs(err, os.ErrNotExist)
+}
+
+func (s *Suite) TestCall() {
+	interp := monkey.New()
+
+	_, err := interp.Run(`let greet = fn(name, times) { if (times > 1) { name + "!" } else { name } }`)
+	s.Require().NoError(err)
+
+	result, err := interp.Call("greet", "monkey", 2)
+	s.Require().NoError(err)
+	s.Require().Equal("monkey!", result.Inspect())
+
+	result, err = interp.Call("len", []string{"a", "b"})
+	s.Require().NoError(err)
+	s.Require().Equal("2", result.Inspect())
+
+	_, err = interp.Call("missing")
+	s.Require().EqualError(err, "identifier not found: missing")
+
+	_, err = interp.Run(`let answer = 42`)
+	s.Require().NoError(err)
+	_, err = interp.Call("answer")
+	s.Require().EqualError(err, "not a function: INTEGER")
+
+	_, err = interp.Call("greet", "monkey", true)
+	s.Require().EqualError(err, "type mismatch: BOOLEAN > INTEGER")
+}
+
+// point implements object.Object with value receivers
+type point struct{ X, Y int }
+
+func (p point) Type() object.ObjectType { return "POINT" }
+func (p point) Inspect() string         { return fmt.Sprintf("point(%d, %d)", p.X, p.Y) }
+
+func (s *Suite) TestToObjectValueReceivers() {
+	obj, err := monkey.ToObject(point{1, 2})
+	s.Require().NoError(err)
+	s.Require().Equal("point(1, 2)", obj.Inspect())
+
+	obj, err = monkey.ToObject([]point{{3, 4}})
+	s.Require().NoError(err)
+	s.Require().Equal("[point(3, 4)]", obj.Inspect())
+
+	obj, err = monkey.ToObject((*point)(nil))
+	s.Require().NoError(err)
+	s.Require().Equal("null", obj.Inspect())
+}
+
+type node struct {
+	Value int
+	Next  *node
+}
+
+func (s *Suite) TestToObjectCycles() {
+	n := &node{Value: 1}
+	n.Next = n
+	_, err := monkey.ToObject(n)
+	s.Require().EqualError(err, "field Next: cannot convert *monkey_test.node containing itself")
+
+	m := map[string]any{}
+	m["self"] = m
+	_, err = monkey.ToObject(m)
+	s.Require().EqualError(err, "key self: cannot convert map[string]interface {} containing itself")
+
+	list := []any{1, nil}
+	list[1] = list
+	_, err = monkey.ToObject(list)
+	s.Require().EqualError(err, "index 1: cannot convert []interface {} containing itself")
+
+	// values referred to more than once are fine as long as they do not contain themselves
+	shared := &node{Value: 2}
+	obj, err := monkey.ToObject([]*node{shared, {Value: 3, Next: shared}})
+	s.Require().NoError(err)
+	s.Require().Equal(`[{"Value": 2, "Next": null}, {"Value": 3, "Next": {"Value": 2, "Next": null}}]`, obj.Inspect())
+
+	interp := monkey.New()
+	s.Require().NoError(interp.Register("ring", func() *node { return n }))
+	s.Require().Equal(
+		"ERROR: result of `ring`: field Next: cannot convert *monkey_test.node containing itself",
+		eval(interp, "ring()").Inspect(),
+	)
+}
+
+func (s *Suite) TestSetOutput() {
+	var first, second bytes.Buffer
+
+	a := monkey.New()
+	a.SetOutput(&first)
+	b := monkey.New()
+	b.SetOutput(&second)
+
+	_, err := a.Run(`let f = fn(x) { puts(x) }; f("a")`)
+	s.Require().NoError(err)
+	_, err = b.Run(`puts("b", 1)`)
+	s.Require().NoError(err)
+	_, err = a.Call("puts", "call")
+	s.Require().NoError(err)
+	_, err = a.Call("f", "fn")
+	s.Require().NoError(err)
+
+	s.Require().Equal("a\ncall\nfn\n", first.String())
+	s.Require().Equal("b\n1\n", second.String())
+}
+
+func (s *Suite) TestGetSet() {
+	interp := monkey.New()
+
+	s.Require().NoError(interp.Set("limits", map[string]int{"max": 10, "min": 1}))
+
+	result, err := interp.Run(`let range = limits["max"] - limits["min"]; range`)
+	s.Require().NoError(err)
+	s.Require().Equal("9", result.Inspect())
+
+	value, ok := interp.Get("range")
+	s.Require().True(ok)
+	s.Require().Equal("9", value.Inspect())
+
+	_, ok = interp.Get("missing")
+	s.Require().False(ok)
+
+	s.Require().EqualError(interp.Set("bad", 1+2i), "cannot set bad: unsupported Go type complex128")
+
+	_, err = interp.Run(`const limit = 5; let mutable = 1;`)
+	s.Require().NoError(err)
+
+	err = interp.Set("limit", 6)
+	s.Require().EqualError(err, "cannot set limit: constant")
+	s.Require().ErrorIs(err, object.ErrConstant)
+	err = interp.Register("limit", func() int { return 6 })
+	s.Require().EqualError(err, "cannot register limit: constant")
+	s.Require().ErrorIs(err, object.ErrConstant)
+
+	value, _ = interp.Get("limit")
+	s.Require().Equal("5", value.Inspect())
+	_, err = interp.Run(`limit = 7`)
+	s.Require().EqualError(err, "cannot assign to constant limit")
+
+	s.Require().NoError(interp.Set("mutable", 2))
+	value, _ = interp.Get("mutable")
+	s.Require().Equal("2", value.Inspect())
+}
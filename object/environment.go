@@ -1,12 +1,44 @@
 package object
 
+import (
+	"errors"
+	"io"
+	"os"
+	"sort"
+)
+
+// errors returned by Environment.Assign
+var (
+	ErrUndefined = errors.New("undefined variable")
+	ErrConstant  = errors.New("constant")
+)
+
+// IntegerOverflow selects what happens when integer arithmetic does not fit in 64 bits
+type IntegerOverflow int
+
+const (
+	// PromoteOnOverflow turns the result into a BigInt
+	PromoteOnOverflow IntegerOverflow = iota
+	// ErrorOnOverflow turns the result into an overflow error
+	ErrorOnOverflow
+)
+
+// binding is the value of a name in a scope and whether it may be changed
+type binding struct {
+	value    Object
+	constant bool
+}
+
 type Environment struct {
-	store map[string]Object
-	outer *Environment
+	store    map[string]binding
+	outer    *Environment
+	overflow IntegerOverflow // only used in the outermost environment
+	calls    int             // function calls in progress, only used in the outermost environment
+	output   io.Writer       // only used in the outermost environment, nil for os.Stdout
 }
 
 func NewEnvironment() *Environment {
-	s := make(map[string]Object)
+	s := make(map[string]binding)
 	return &Environment{store: s}
 }
 
@@ -16,17 +48,111 @@ func NewEnclosedEnvironment(outer *Environment) *Environment {
 	return env
 }
 
+func (e *Environment) outermost() *Environment {
+	for e.outer != nil {
+		e = e.outer
+	}
+	return e
+}
+
+// IntegerOverflow returns the overflow behaviour of the outermost environment
+func (e *Environment) IntegerOverflow() IntegerOverflow {
+	return e.outermost().overflow
+}
+
+// Output returns the writer of the outermost environment that builtins like puts write to
+func (e *Environment) Output() io.Writer {
+	if output := e.outermost().output; output != nil {
+		return output
+	}
+	return os.Stdout
+}
+
+// SetOutput changes where builtins write for code evaluated in this environment and the environments
+// enclosed by it, it must be called on the outermost environment
+func (e *Environment) SetOutput(w io.Writer) {
+	e.output = w
+}
+
+// EnterCall records the start of a function call in the outermost environment, it reports false and
+// records nothing if limit calls are already in progress
+func (e *Environment) EnterCall(limit int) bool {
+	outer := e.outermost()
+	if outer.calls >= limit {
+		return false
+	}
+	outer.calls++
+	return true
+}
+
+// LeaveCall records the end of a call started with EnterCall
+func (e *Environment) LeaveCall() {
+	e.outermost().calls--
+}
+
+// SetIntegerOverflow changes the overflow behaviour for code evaluated in this environment and the
+// environments enclosed by it, it must be called on the outermost environment
+func (e *Environment) SetIntegerOverflow(overflow IntegerOverflow) {
+	e.overflow = overflow
+}
+
 func (e *Environment) Get(name string) (Object, bool) {
-	obj, ok := e.store[name]
+	b, ok := e.store[name]
 
 	if !ok && e.outer != nil {
-		obj, ok = e.outer.Get(name)
+		return e.outer.Get(name)
 	}
 
-	return obj, ok
+	return b.value, ok
 }
 
+// Set binds name to a mutable value in this scope, replacing any previous binding including constants
 func (e *Environment) Set(name string, val Object) Object {
-	e.store[name] = val
+	e.store[name] = binding{value: val}
 	return val
 }
+
+// Declare binds name in this scope like a let or const statement, it reports false and leaves the scope
+// unchanged if name already is a constant of this scope
+func (e *Environment) Declare(name string, val Object, constant bool) bool {
+	if e.store[name].constant {
+		return false
+	}
+	e.store[name] = binding{value: val, constant: constant}
+	return true
+}
+
+// IsConst reports whether name is bound to a constant in the innermost scope that defines it
+func (e *Environment) IsConst(name string) bool {
+	for env := e; env != nil; env = env.outer {
+		if b, ok := env.store[name]; ok {
+			return b.constant
+		}
+	}
+	return false
+}
+
+// Assign updates the binding of name in the innermost scope that defines it. It returns ErrUndefined if
+// no scope does and ErrConstant if the binding is a constant.
+func (e *Environment) Assign(name string, val Object) error {
+	for env := e; env != nil; env = env.outer {
+		if b, ok := env.store[name]; ok {
+			if b.constant {
+				return ErrConstant
+			}
+			env.store[name] = binding{value: val}
+			return nil
+		}
+	}
+	return ErrUndefined
+}
+
+// Names returns the sorted names bound in this scope, bindings of outer scopes are not included
+func (e *Environment) Names() []string {
+	names := make([]string, 0, len(e.store))
+	for name := range e.store {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,93 @@
+package object
+
+import (
+	"fmt"
+	"hash/fnv"
+)
+
+// HashKey identifies a hashable object, two objects with equal values have equal hash keys
+type HashKey struct {
+	Type  ObjectType
+	Value uint64
+}
+
+// Hashable is implemented by objects that can be used as hash keys
+type Hashable interface {
+	HashKey() HashKey
+}
+
+func (i *Integer) HashKey() HashKey {
+	return HashKey{Type: i.Type(), Value: uint64(i.Value)}
+}
+
+func (b *BigInt) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(b.Value.String()))
+	return HashKey{Type: b.Type(), Value: h.Sum64()}
+}
+
+func (b *Boolean) HashKey() HashKey {
+	var value uint64
+	if b.Value {
+		value = 1
+	}
+	return HashKey{Type: b.Type(), Value: value}
+}
+
+func (s *String) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(s.Value))
+	return HashKey{Type: s.Type(), Value: h.Sum64()}
+}
+
+type HashPair struct {
+	Key   Object
+	Value Object
+}
+
+// Hash maps hashable keys to values and remembers the order in which keys were inserted
+type Hash struct {
+	pairs map[HashKey]HashPair
+	keys  []HashKey
+}
+
+func NewHash() *Hash {
+	return &Hash{pairs: make(map[HashKey]HashPair)}
+}
+
+// Get returns the pair stored under the given key
+func (h *Hash) Get(key HashKey) (HashPair, bool) {
+	pair, ok := h.pairs[key]
+	return pair, ok
+}
+
+// Set stores the pair under the given key, new keys are appended to the insertion order
+func (h *Hash) Set(key HashKey, pair HashPair) {
+	if _, ok := h.pairs[key]; !ok {
+		h.keys = append(h.keys, key)
+	}
+	h.pairs[key] = pair
+}
+
+// Len returns the number of pairs in the hash
+func (h *Hash) Len() int { return len(h.keys) }
+
+// Pairs returns the pairs of the hash in insertion order
+func (h *Hash) Pairs() []HashPair {
+	pairs := make([]HashPair, 0, len(h.keys))
+	for _, key := range h.keys {
+		pairs = append(pairs, h.pairs[key])
+	}
+	return pairs
+}
+
+func (h *Hash) Type() ObjectType { return HASH_OBJ }
+func (h *Hash) Inspect() string  { return inspect(h, map[Object]bool{}) }
+
+// inspectKey quotes string keys so that {"1": 1} and {1: 1} can be told apart
+func inspectKey(key Object) string {
+	if str, ok := key.(*String); ok {
+		return fmt.Sprintf("%q", str.Value)
+	}
+	return key.Inspect()
+}
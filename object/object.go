@@ -3,7 +3,9 @@ package object
 import (
 	"bytes"
 	"fmt"
+	"math/big"
 	"monkey/ast"
+	"strconv"
 	"strings"
 )
 
@@ -11,12 +13,20 @@ type ObjectType string
 
 const (
 	INTEGER_OBJ      = "INTEGER"
+	FLOAT_OBJ        = "FLOAT"
+	BIGINT_OBJ       = "BIGINT"
 	BOOLEAN_OBJ      = "BOOLEAN"
 	NULL_OBJ         = "NULL"
 	RETURN_VALUE_OBJ = "RETURN_VALUE"
+	BREAK_OBJ        = "BREAK"
+	CONTINUE_OBJ     = "CONTINUE"
 	ERROR_OBJ        = "ERROR"
 	FUNCTION_OBJ     = "FUNCTION"
 	STRING_OBJ       = "STRING"
+	ARRAY_OBJ        = "ARRAY"
+	HASH_OBJ         = "HASH"
+	BUILTIN_OBJ      = "BUILTIN"
+	RANGE_OBJ        = "RANGE"
 )
 
 type Object interface {
@@ -31,6 +41,29 @@ type Integer struct {
 func (i *Integer) Inspect() string  { return fmt.Sprintf("%d", i.Value) }
 func (i *Integer) Type() ObjectType { return INTEGER_OBJ }
 
+// BigInt is an integer that does not fit in an int64. Arithmetic results that fit are turned back
+// into an Integer so every value has a single representation. The value must not be modified.
+type BigInt struct {
+	Value *big.Int
+}
+
+func (b *BigInt) Inspect() string  { return b.Value.String() }
+func (b *BigInt) Type() ObjectType { return BIGINT_OBJ }
+
+type Float struct {
+	Value float64
+}
+
+// Inspect formats the float so it can be told apart from an integer, e.g. 3.0 instead of 3
+func (f *Float) Inspect() string {
+	s := strconv.FormatFloat(f.Value, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".eIN") {
+		s += ".0"
+	}
+	return s
+}
+func (f *Float) Type() ObjectType { return FLOAT_OBJ }
+
 type Boolean struct {
 	Value bool
 }
@@ -50,6 +83,49 @@ type ReturnValue struct {
 func (rv *ReturnValue) Type() ObjectType { return RETURN_VALUE_OBJ }
 func (rv *ReturnValue) Inspect() string  { return rv.Value.Inspect() }
 
+// Break is the result of a break statement, it propagates like a ReturnValue up to the enclosing loop
+type Break struct{}
+
+func (b *Break) Type() ObjectType { return BREAK_OBJ }
+func (b *Break) Inspect() string  { return "break" }
+
+// Continue is the result of a continue statement, it propagates like a ReturnValue up to the enclosing loop
+type Continue struct{}
+
+func (c *Continue) Type() ObjectType { return CONTINUE_OBJ }
+func (c *Continue) Inspect() string  { return "continue" }
+
+// Range is the sequence of integers from Start up to but not including End in increments of Step, Step
+// is never zero and may be negative to count down
+type Range struct {
+	Start int64
+	End   int64
+	Step  int64
+}
+
+func (r *Range) Type() ObjectType { return RANGE_OBJ }
+func (r *Range) Inspect() string {
+	return fmt.Sprintf("range(%d, %d, %d)", r.Start, r.End, r.Step)
+}
+
+// Len returns the number of integers in the range, which may not fit in an int64
+func (r *Range) Len() uint64 {
+	// the differences are computed modulo 2^64 so they cannot overflow
+	switch {
+	case r.Step > 0 && r.Start < r.End:
+		return (uint64(r.End)-uint64(r.Start)-1)/uint64(r.Step) + 1
+	case r.Step < 0 && r.Start > r.End:
+		return (uint64(r.Start)-uint64(r.End)-1)/(-uint64(r.Step)) + 1
+	default:
+		return 0
+	}
+}
+
+// At returns the integer at index i, which must be less than Len
+func (r *Range) At(i uint64) int64 {
+	return int64(uint64(r.Start) + i*uint64(r.Step))
+}
+
 type Error struct {
 	Message string
 }
@@ -82,9 +158,63 @@ func (f *Function) Inspect() string {
 	return out.String()
 }
 
+type BuiltinFunction func(args ...Object) Object
+
+// Builtin wraps a function implemented in Go so it can be called from Monkey code
+type Builtin struct {
+	Name string
+	Fn   BuiltinFunction
+	// EnvFn is called instead of Fn if it is set, it receives the environment of the caller for builtins
+	// such as puts which depend on it
+	EnvFn func(env *Environment, args ...Object) Object
+}
+
+func (b *Builtin) Type() ObjectType { return BUILTIN_OBJ }
+func (b *Builtin) Inspect() string  { return "builtin function " + b.Name }
+
 type String struct {
 	Value string
 }
 
 func (s *String) Type() ObjectType { return STRING_OBJ }
 func (s *String) Inspect() string  { return s.Value }
+
+type Array struct {
+	Elements []Object
+}
+
+func (a *Array) Type() ObjectType { return ARRAY_OBJ }
+func (a *Array) Inspect() string  { return inspect(a, map[Object]bool{}) }
+
+// inspect formats the object like Inspect, arrays and hashes which are already being formatted by an
+// enclosing call are printed as [...] and {...} so that values containing themselves can be printed
+func inspect(obj Object, visiting map[Object]bool) string {
+	switch obj := obj.(type) {
+	case *Array:
+		if visiting[obj] {
+			return "[...]"
+		}
+		visiting[obj] = true
+		defer delete(visiting, obj)
+
+		elements := []string{}
+		for _, e := range obj.Elements {
+			elements = append(elements, inspect(e, visiting))
+		}
+		return "[" + strings.Join(elements, ", ") + "]"
+	case *Hash:
+		if visiting[obj] {
+			return "{...}"
+		}
+		visiting[obj] = true
+		defer delete(visiting, obj)
+
+		pairs := []string{}
+		for _, pair := range obj.Pairs() {
+			pairs = append(pairs, fmt.Sprintf("%s: %s", inspectKey(pair.Key), inspect(pair.Value, visiting)))
+		}
+		return "{" + strings.Join(pairs, ", ") + "}"
+	default:
+		return obj.Inspect()
+	}
+}
@@ -0,0 +1,157 @@
+package object_test
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"monkey/object"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type Suite struct {
+	suite.Suite
+}
+
+func (s *Suite) SetupTest() {
+}
+
+func TestRunSuite(t *testing.T) {
+	suite.Run(t, new(Suite))
+}
+
+func (s *Suite) TestStringHashKey() {
+	hello1 := &object.String{Value: "Hello World"}
+	hello2 := &object.String{Value: "Hello World"}
+	diff1 := &object.String{Value: "My name is johnny"}
+	diff2 := &object.String{Value: "My name is johnny"}
+
+	s.Require().Equal(hello1.HashKey(), hello2.HashKey())
+	s.Require().Equal(diff1.HashKey(), diff2.HashKey())
+	s.Require().NotEqual(hello1.HashKey(), diff1.HashKey())
+}
+
+func (s *Suite) TestHashKeyTypesDiffer() {
+	one := &object.Integer{Value: 1}
+	yes := &object.Boolean{Value: true}
+
+	s.Require().NotEqual(one.HashKey(), yes.HashKey())
+}
+
+func (s *Suite) TestBigIntHashKey() {
+	value, _ := new(big.Int).SetString("99999999999999999999", 10)
+	same, _ := new(big.Int).SetString("99999999999999999999", 10)
+	other, _ := new(big.Int).SetString("99999999999999999998", 10)
+
+	big1 := &object.BigInt{Value: value}
+	big2 := &object.BigInt{Value: same}
+	diff := &object.BigInt{Value: other}
+
+	s.Require().Equal(big1.HashKey(), big2.HashKey())
+	s.Require().NotEqual(big1.HashKey(), diff.HashKey())
+	s.Require().Equal("99999999999999999999", big1.Inspect())
+}
+
+func (s *Suite) TestFloatInspect() {
+	tests := []struct {
+		value    float64
+		expected string
+	}{
+		{3, "3.0"},
+		{-0.5, "-0.5"},
+		{3.14, "3.14"},
+		{1e21, "1e+21"},
+		{1e-9, "1e-09"},
+		{math.Inf(1), "+Inf"},
+		{math.NaN(), "NaN"},
+	}
+
+	for _, tt := range tests {
+		s.Require().Equal(tt.expected, (&object.Float{Value: tt.value}).Inspect())
+	}
+}
+
+func (s *Suite) TestHashInsertionOrder() {
+	hash := object.NewHash()
+	keys := []object.Object{
+		&object.String{Value: "b"},
+		&object.Integer{Value: 1},
+		&object.String{Value: "a"},
+	}
+
+	for i, key := range keys {
+		hash.Set(key.(object.Hashable).HashKey(), object.HashPair{Key: key, Value: &object.Integer{Value: int64(i)}})
+	}
+	// overwriting a key keeps its original position
+	hash.Set(keys[0].(object.Hashable).HashKey(), object.HashPair{Key: keys[0], Value: &object.Integer{Value: 9}})
+
+	s.Require().Equal(3, hash.Len())
+	s.Require().Equal(`{"b": 9, 1: 1, "a": 2}`, hash.Inspect())
+}
+
+func (s *Suite) TestEnvironmentConstants() {
+	outer := object.NewEnvironment()
+	s.Require().True(outer.Declare("x", &object.Integer{Value: 1}, true))
+	s.Require().False(outer.Declare("x", &object.Integer{Value: 2}, false))
+	s.Require().Equal(object.ErrConstant, outer.Assign("x", &object.Integer{Value: 3}))
+	s.Require().Equal(object.ErrUndefined, outer.Assign("y", &object.Integer{Value: 3}))
+
+	inner := object.NewEnclosedEnvironment(outer)
+	s.Require().True(inner.IsConst("x"))
+	s.Require().True(inner.Declare("x", &object.Integer{Value: 4}, false))
+	s.Require().False(inner.IsConst("x"))
+	s.Require().NoError(inner.Assign("x", &object.Integer{Value: 5}))
+
+	val, _ := outer.Get("x")
+	s.Require().Equal("1", val.Inspect())
+	val, _ = inner.Get("x")
+	s.Require().Equal("5", val.Inspect())
+}
+
+func (s *Suite) TestRangeLen() {
+	tests := []struct {
+		rng      object.Range
+		expected uint64
+		last     int64
+	}{
+		{object.Range{Start: 0, End: 5, Step: 1}, 5, 4},
+		{object.Range{Start: 0, End: 10, Step: 3}, 4, 9},
+		{object.Range{Start: 10, End: 0, Step: -4}, 3, 2},
+		{object.Range{Start: math.MaxInt64 - 2, End: math.MaxInt64, Step: 5}, 1, math.MaxInt64 - 2},
+		{object.Range{Start: math.MinInt64, End: math.MaxInt64, Step: math.MaxInt64}, 3, math.MaxInt64 - 1},
+		{object.Range{Start: math.MaxInt64, End: math.MinInt64, Step: math.MinInt64}, 2, -1},
+	}
+
+	for _, tt := range tests {
+		s.Require().Equal(tt.expected, tt.rng.Len(), tt.rng.Inspect())
+		s.Require().Equal(tt.last, tt.rng.At(tt.expected-1), tt.rng.Inspect())
+	}
+
+	s.Require().Zero((&object.Range{Start: 1, End: 1, Step: 1}).Len())
+}
+
+func (s *Suite) TestInspectCyclicValues() {
+	array := &object.Array{Elements: []object.Object{&object.Integer{Value: 1}}}
+	array.Elements = append(array.Elements, array)
+
+	hash := object.NewHash()
+	key := &object.String{Value: "self"}
+	hash.Set(key.HashKey(), object.HashPair{Key: key, Value: hash})
+	array.Elements = append(array.Elements, hash)
+
+	s.Require().Equal(`[1, [...], {"self": {...}}]`, array.Inspect())
+	s.Require().Equal(`{"self": {...}}`, hash.Inspect())
+}
+
+func (s *Suite) TestEnvironmentCallDepth() {
+	outer := object.NewEnvironment()
+	inner := object.NewEnclosedEnvironment(outer)
+
+	s.Require().True(outer.EnterCall(2))
+	s.Require().True(inner.EnterCall(2))
+	s.Require().False(inner.EnterCall(2))
+
+	outer.LeaveCall()
+	s.Require().True(outer.EnterCall(2))
+}
@@ -0,0 +1,73 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"monkey/token"
+)
+
+// ParseError describes a single syntax error found while parsing
+type ParseError struct {
+	Pos      token.Position    // where the error occurred
+	Expected []token.TokenType // token types that would have been accepted, may be empty
+	Actual   token.Token       // the offending token
+	Message  string
+}
+
+func (e *ParseError) Error() string {
+	if e.Pos.IsValid() {
+		return e.Pos.String() + ": " + e.Message
+	}
+	return e.Message
+}
+
+// RenderErrors writes every error followed by the offending source line and a caret pointing at the error
+func RenderErrors(out io.Writer, source string, errors []*ParseError) {
+	lines := strings.Split(source, "\n")
+
+	for _, err := range errors {
+		io.WriteString(out, err.Error()+"\n")
+
+		if !err.Pos.IsValid() || err.Pos.Line > len(lines) {
+			continue
+		}
+
+		line := strings.TrimRight(lines[err.Pos.Line-1], "\r")
+		gutter := fmt.Sprintf("%4d | ", err.Pos.Line)
+		io.WriteString(out, gutter+line+"\n")
+		io.WriteString(out, strings.Repeat(" ", len(gutter)-2)+"| "+caretLine(line, err)+"\n")
+	}
+}
+
+// caretLine builds the marker line underneath the source line, keeping tabs so the caret lines up
+func caretLine(line string, err *ParseError) string {
+	var out strings.Builder
+
+	// columns count runes, not bytes
+	runes := []rune(line)
+	column := err.Pos.Column - 1
+	for i := 0; i < column && i < len(runes); i++ {
+		if runes[i] == '\t' {
+			out.WriteByte('\t')
+		} else {
+			out.WriteByte(' ')
+		}
+	}
+	for i := len(runes); i < column; i++ {
+		out.WriteByte(' ')
+	}
+
+	out.WriteByte('^')
+
+	// underline the rest of the offending token when it is on the same line
+	if err.Actual.End.Line == err.Pos.Line && err.Actual.Pos == err.Pos {
+		width := err.Actual.End.Column - err.Pos.Column
+		if width > 1 {
+			out.WriteString(strings.Repeat("~", width-1))
+		}
+	}
+
+	return out.String()
+}
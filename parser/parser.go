@@ -1,7 +1,9 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
+	"math/big"
 	"strconv"
 
 	"monkey/ast"
@@ -9,15 +11,23 @@ import (
 	"monkey/token"
 )
 
+// maxErrors is the number of errors after which the parser gives up
+const maxErrors = 10
+
 const (
 	_ int = iota
 	LOWEST
+	ASSIGN      // x = y or x += y
+	OR          // ||
+	AND         // &&
 	EQUALS      // ==
 	LESSGREATER // > or <
-	SUM         // +
-	PRODUCT     // *
+	SUM         // + or |
+	PRODUCT     // * or <<
 	PREFIX      // -X or !X
+	POWER       // X ** Y, binds tighter than a prefix operator on its left
 	CALL        // myFunction(X)
+	INDEX       // array[index]
 )
 
 type Parser struct {
@@ -26,7 +36,17 @@ type Parser struct {
 	curToken  token.Token
 	peekToken token.Token
 
-	errors []string
+	errors []*ParseError
+	// panicking is set when an error is reported and cleared once the parser has
+	// synchronized on the next statement, errors reported in between are dropped
+	panicking bool
+	// braceDepth is the number of unclosed { up to and including the current token
+	braceDepth int
+	// scopes holds the names declared so far in the program and the enclosing function bodies, innermost
+	// last, mapped to whether they are constants
+	scopes []map[string]bool
+	// loopDepth is the number of loops enclosing the current token within the innermost function body
+	loopDepth int
 
 	prefixParsFns map[token.TokenType]prefixParseFn
 	infixParseFns map[token.TokenType]infixParseFn
@@ -40,7 +60,8 @@ type (
 func New(lex *lexer.Lexer) *Parser {
 	p := &Parser{
 		lex:    lex,
-		errors: []string{},
+		errors: []*ParseError{},
+		scopes: []map[string]bool{{}},
 	}
 
 	// read two tokens to set curToken and peek token
@@ -50,6 +71,7 @@ func New(lex *lexer.Lexer) *Parser {
 	p.prefixParsFns = make(map[token.TokenType]prefixParseFn)
 	p.registerPrefix(token.IDENT, p.parseIdentifier)
 	p.registerPrefix(token.INT, p.parseIntegerLiteral)
+	p.registerPrefix(token.FLOAT, p.parseFloatLiteral)
 	p.registerPrefix(token.BANG, p.parsePrefixExpression)
 	p.registerPrefix(token.MINUS, p.parsePrefixExpression)
 	p.registerPrefix(token.TRUE, p.parseBooleanLiteral)
@@ -58,6 +80,9 @@ func New(lex *lexer.Lexer) *Parser {
 	p.registerPrefix(token.IF, p.parseIfExpression)
 	p.registerPrefix(token.FUNCTION, p.parseFunctionLiteral)
 	p.registerPrefix(token.STRING, p.parseStringLiteral)
+	p.registerPrefix(token.LBRACKET, p.parseArrayLiteral)
+	p.registerPrefix(token.LBRACE, p.parseHashLiteral)
+	p.registerPrefix(token.ILLEGAL, p.parseIllegal)
 
 	p.infixParseFns = make(map[token.TokenType]infixParseFn)
 	p.registerInfix(token.PLUS, p.parseInfixExpression)
@@ -68,7 +93,24 @@ func New(lex *lexer.Lexer) *Parser {
 	p.registerInfix(token.NOT_EQ, p.parseInfixExpression)
 	p.registerInfix(token.LT, p.parseInfixExpression)
 	p.registerInfix(token.GT, p.parseInfixExpression)
+	p.registerInfix(token.LT_EQ, p.parseInfixExpression)
+	p.registerInfix(token.GT_EQ, p.parseInfixExpression)
+	p.registerInfix(token.PERCENT, p.parseInfixExpression)
+	p.registerInfix(token.POWER, p.parseInfixExpression)
+	p.registerInfix(token.AND, p.parseInfixExpression)
+	p.registerInfix(token.OR, p.parseInfixExpression)
+	p.registerInfix(token.AMPERSAND, p.parseInfixExpression)
+	p.registerInfix(token.PIPE, p.parseInfixExpression)
+	p.registerInfix(token.CARET, p.parseInfixExpression)
+	p.registerInfix(token.SHIFT_LEFT, p.parseInfixExpression)
+	p.registerInfix(token.SHIFT_RIGHT, p.parseInfixExpression)
+	p.registerInfix(token.ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.PLUS_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.MINUS_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.ASTERISK_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.SLASH_ASSIGN, p.parseAssignExpression)
 	p.registerInfix(token.LPAREN, p.parseCallExpression)
+	p.registerInfix(token.LBRACKET, p.parseIndexExpression)
 
 	return p
 }
@@ -84,6 +126,19 @@ func (p *Parser) registerInfix(tokenType token.TokenType, fn infixParseFn) {
 func (p *Parser) nextToken() {
 	p.curToken = p.peekToken
 	p.peekToken = p.lex.NextToken()
+	// comments are only of interest to tools running the lexer in lexer.ScanComments mode
+	for p.peekToken.Type == token.COMMENT {
+		p.peekToken = p.lex.NextToken()
+	}
+
+	switch p.curToken.Type {
+	case token.LBRACE:
+		p.braceDepth += 1
+	case token.RBRACE:
+		if p.braceDepth > 0 {
+			p.braceDepth -= 1
+		}
+	}
 }
 
 func (p *Parser) curTokenIs(t token.TokenType) bool {
@@ -104,30 +159,82 @@ func (p *Parser) expectPeek(t token.TokenType) bool {
 	}
 }
 
+// Errors returns the parser errors formatted as "line:column: message"
 func (p *Parser) Errors() []string {
+	msgs := make([]string, 0, len(p.errors))
+	for _, err := range p.errors {
+		msgs = append(msgs, err.Error())
+	}
+	return msgs
+}
+
+// ParseErrors returns the structured parser errors
+func (p *Parser) ParseErrors() []*ParseError {
 	return p.errors
 }
 
+// addError records an error located at the given token
+func (p *Parser) addError(tok token.Token, expected []token.TokenType, msg string) {
+	if p.panicking || p.tooManyErrors() {
+		return
+	}
+	p.panicking = true
+
+	if len(p.errors) == maxErrors {
+		msg = "too many errors"
+		expected = nil
+	}
+
+	p.errors = append(p.errors, &ParseError{
+		Pos:      tok.Pos,
+		Expected: expected,
+		Actual:   tok,
+		Message:  msg,
+	})
+}
+
+// tooManyErrors reports whether the error limit has been reached and parsing should stop
+func (p *Parser) tooManyErrors() bool {
+	return len(p.errors) > maxErrors
+}
+
 func (p *Parser) peekError(t token.TokenType) {
 	msg := fmt.Sprintf("expected next token to be %s, got %s instead", t, p.peekToken.Type)
-	p.errors = append(p.errors, msg)
+	p.addError(p.peekToken, []token.TokenType{t}, msg)
 }
 
 func (p *Parser) noPrefixParsFnError(t token.TokenType) {
 	msg := fmt.Sprintf("no prefix parse function for %s found", t)
-	p.errors = append(p.errors, msg)
+	p.addError(p.curToken, nil, msg)
 }
 
 var precedences = map[token.TokenType]int{
-	token.EQ:       EQUALS,
-	token.NOT_EQ:   EQUALS,
-	token.LT:       LESSGREATER,
-	token.GT:       LESSGREATER,
-	token.PLUS:     SUM,
-	token.MINUS:    SUM,
-	token.SLASH:    PRODUCT,
-	token.ASTERISK: PRODUCT,
-	token.LPAREN:   CALL,
+	token.ASSIGN:          ASSIGN,
+	token.PLUS_ASSIGN:     ASSIGN,
+	token.MINUS_ASSIGN:    ASSIGN,
+	token.ASTERISK_ASSIGN: ASSIGN,
+	token.SLASH_ASSIGN:    ASSIGN,
+	token.OR:              OR,
+	token.AND:             AND,
+	token.EQ:              EQUALS,
+	token.NOT_EQ:          EQUALS,
+	token.LT:              LESSGREATER,
+	token.GT:              LESSGREATER,
+	token.LT_EQ:           LESSGREATER,
+	token.GT_EQ:           LESSGREATER,
+	token.PLUS:            SUM,
+	token.MINUS:           SUM,
+	token.PIPE:            SUM,
+	token.CARET:           SUM,
+	token.SLASH:           PRODUCT,
+	token.ASTERISK:        PRODUCT,
+	token.PERCENT:         PRODUCT,
+	token.AMPERSAND:       PRODUCT,
+	token.SHIFT_LEFT:      PRODUCT,
+	token.SHIFT_RIGHT:     PRODUCT,
+	token.POWER:           POWER,
+	token.LPAREN:          CALL,
+	token.LBRACKET:        INDEX,
 }
 
 func (p *Parser) peekPrecedence() int {
@@ -148,46 +255,114 @@ func (p *Parser) ParseProgram() *ast.Program {
 	program := &ast.Program{}
 	program.Statements = []ast.Statement{}
 
-	for !p.curTokenIs(token.EOF) {
-		stmt := p.parseStatement()
-		if stmt != nil {
-			program.Statements = append(program.Statements, stmt)
-		}
+	for !p.curTokenIs(token.EOF) && !p.tooManyErrors() {
+		stmt := p.parseStatementWithRecovery()
+		program.Statements = append(program.Statements, stmt)
 		p.nextToken()
 	}
 
 	return program
 }
 
+// parseStatementWithRecovery parses a statement and, if it contains a syntax error,
+// skips ahead to the end of the statement and returns an ast.BadStatement instead
+func (p *Parser) parseStatementWithRecovery() ast.Statement {
+	start := p.curToken
+	depth := p.braceDepth
+	if start.Type == token.LBRACE {
+		depth -= 1
+	}
+
+	stmt := p.parseStatement()
+	if !p.panicking {
+		return stmt
+	}
+
+	p.synchronize(depth)
+	p.panicking = false
+
+	return p.badStatement(start)
+}
+
+// synchronize advances until the current token ends the broken statement which started at the given
+// brace depth: it stops in front of a statement keyword or the } closing the enclosing block, or on a ;
+// that is not nested inside braces opened by the statement
+func (p *Parser) synchronize(depth int) {
+	if p.curTokenIs(token.SEMICOLON) && p.braceDepth == depth {
+		// the statement parser already consumed the terminating ;
+		return
+	}
+
+	for !p.peekTokenIs(token.EOF) {
+		if p.braceDepth == depth {
+			switch p.peekToken.Type {
+			case token.RBRACE:
+				if depth > 0 {
+					return
+				}
+				// a stray } at the top level does not close anything, skip it as part of the statement
+			case token.SEMICOLON:
+				p.nextToken()
+				return
+			case token.LET, token.CONST, token.RETURN, token.WHILE, token.FOR, token.BREAK, token.CONTINUE:
+				return
+			}
+		}
+		p.nextToken()
+	}
+}
+
+// badStatement returns a placeholder for a statement starting at the given token and ending at the current token
+func (p *Parser) badStatement(start token.Token) ast.Statement {
+	return &ast.BadStatement{Token: start, To: p.curToken.End}
+}
+
+// badExpression returns a placeholder for an expression starting at the given token and ending at the current token
+func (p *Parser) badExpression(start token.Token) ast.Expression {
+	return &ast.BadExpression{Token: start, To: p.curToken.End}
+}
+
 func (p *Parser) parseStatement() ast.Statement {
 	switch p.curToken.Type {
-	case token.LET:
+	case token.LET, token.CONST:
 		return p.parseLetStatement()
 	case token.RETURN:
 		return p.parseReturnStatement()
+	case token.WHILE:
+		return p.parseWhileStatement()
+	case token.FOR:
+		return p.parseForStatement()
+	case token.BREAK, token.CONTINUE:
+		return p.parseLoopControlStatement()
 	default:
 		return p.parseExpressionStatement()
 	}
 }
 
-func (p *Parser) parseLetStatement() *ast.LetStatement {
+func (p *Parser) parseLetStatement() ast.Statement {
 	defer untrace(trace("parseLetStatement"))
 
 	stmt := &ast.LetStatement{Token: p.curToken}
 
 	if !p.expectPeek(token.IDENT) {
-		return nil
+		return p.badStatement(stmt.Token)
 	}
 
 	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 
+	if p.scopes[len(p.scopes)-1][stmt.Name.Value] {
+		p.addError(p.curToken, nil, fmt.Sprintf("cannot redeclare constant %s", stmt.Name.Value))
+		return p.badStatement(stmt.Token)
+	}
+
 	if !p.expectPeek(token.ASSIGN) {
-		return nil
+		return p.badStatement(stmt.Token)
 	}
 
 	p.nextToken()
 
 	stmt.Value = p.parseExpression(LOWEST)
+	p.scopes[len(p.scopes)-1][stmt.Name.Value] = stmt.IsConst()
 
 	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
@@ -212,6 +387,125 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	return stmt
 }
 
+func (p *Parser) parseWhileStatement() ast.Statement {
+	defer untrace(trace("parseWhileStatement"))
+
+	stmt := &ast.WhileStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return p.badStatement(stmt.Token)
+	}
+
+	p.nextToken()
+	stmt.Condition = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return p.badStatement(stmt.Token)
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return p.badStatement(stmt.Token)
+	}
+
+	stmt.Body = p.parseLoopBody(nil)
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseForStatement() ast.Statement {
+	defer untrace(trace("parseForStatement"))
+
+	stmt := &ast.ForStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return p.badStatement(stmt.Token)
+	}
+
+	if !p.expectPeek(token.IDENT) {
+		return p.badStatement(stmt.Token)
+	}
+	stmt.Value = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	if p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		if !p.expectPeek(token.IDENT) {
+			return p.badStatement(stmt.Token)
+		}
+		stmt.Key = stmt.Value
+		stmt.Value = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	}
+
+	if !p.expectPeek(token.IN) {
+		return p.badStatement(stmt.Token)
+	}
+
+	p.nextToken()
+	stmt.Iterable = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return p.badStatement(stmt.Token)
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return p.badStatement(stmt.Token)
+	}
+
+	names := []*ast.Identifier{stmt.Value}
+	if stmt.Key != nil {
+		names = append(names, stmt.Key)
+	}
+	stmt.Body = p.parseLoopBody(names)
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+// parseLoopBody parses the block of a loop, which is evaluated in a new scope on every iteration where
+// the given names are variables
+func (p *Parser) parseLoopBody(names []*ast.Identifier) *ast.BlockStatement {
+	scope := map[string]bool{}
+	for _, name := range names {
+		scope[name.Value] = false
+	}
+	p.scopes = append(p.scopes, scope)
+	p.loopDepth++
+
+	body := p.parseBlockStatement()
+
+	p.loopDepth--
+	p.scopes = p.scopes[:len(p.scopes)-1]
+
+	return body
+}
+
+// parseLoopControlStatement parses a break or continue statement
+func (p *Parser) parseLoopControlStatement() ast.Statement {
+	defer untrace(trace("parseLoopControlStatement"))
+
+	tok := p.curToken
+
+	if p.loopDepth == 0 {
+		p.addError(tok, nil, fmt.Sprintf("%s outside loop", tok.Literal))
+		return p.badStatement(tok)
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	if tok.Type == token.BREAK {
+		return &ast.BreakStatement{Token: tok}
+	}
+	return &ast.ContinueStatement{Token: tok}
+}
+
 func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
 	defer untrace(trace("parseExpressionStatement"))
 
@@ -233,7 +527,7 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 	prefix := p.prefixParsFns[p.curToken.Type]
 	if prefix == nil {
 		p.noPrefixParsFnError(p.curToken.Type)
-		return nil
+		return p.badExpression(p.curToken)
 	}
 
 	leftExp := prefix()
@@ -263,10 +557,33 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 	lit := &ast.IntegerLiteral{Token: p.curToken}
 
 	value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
+	if errors.Is(err, strconv.ErrRange) {
+		// the evaluator decides whether a big integer is acceptable
+		if lit.Big, _ = new(big.Int).SetString(p.curToken.Literal, 0); lit.Big != nil {
+			return lit
+		}
+	}
 	if err != nil {
-		msg := fmt.Sprintf("coult not parse %q as integer", p.curToken.Literal)
-		p.errors = append(p.errors, msg)
-		return nil
+		msg := fmt.Sprintf("could not parse %q as integer", p.curToken.Literal)
+		p.addError(p.curToken, nil, msg)
+		return p.badExpression(lit.Token)
+	}
+
+	lit.Value = value
+
+	return lit
+}
+
+func (p *Parser) parseFloatLiteral() ast.Expression {
+	defer untrace(trace("parseFloatLiteral"))
+
+	lit := &ast.FloatLiteral{Token: p.curToken}
+
+	value, err := strconv.ParseFloat(p.curToken.Literal, 64)
+	if err != nil {
+		msg := fmt.Sprintf("could not parse %q as float", p.curToken.Literal)
+		p.addError(p.curToken, nil, msg)
+		return p.badExpression(lit.Token)
 	}
 
 	lit.Value = value
@@ -299,12 +616,55 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 	}
 
 	precedence := p.curPrecedence()
+	if p.curTokenIs(token.POWER) {
+		// right-associative, 2 ** 3 ** 2 is 2 ** (3 ** 2)
+		precedence -= 1
+	}
 	p.nextToken()
 	expression.Right = p.parseExpression(precedence)
 
 	return expression
 }
 
+func (p *Parser) parseAssignExpression(target ast.Expression) ast.Expression {
+	defer untrace(trace("parseAssignExpression"))
+
+	expression := &ast.AssignExpression{
+		Token:    p.curToken,
+		Target:   target,
+		Operator: p.curToken.Literal,
+	}
+
+	switch target := target.(type) {
+	case *ast.Identifier:
+		if p.isConst(target.Value) {
+			p.addError(target.Token, nil, fmt.Sprintf("cannot assign to constant %s", target.Value))
+			return p.badExpression(target.Token)
+		}
+	case *ast.IndexExpression:
+	default:
+		start := token.Token{Type: token.ILLEGAL, Pos: target.Pos(), End: target.End()}
+		p.addError(start, nil, fmt.Sprintf("cannot assign to %s", target.String()))
+		return p.badExpression(start)
+	}
+
+	// right-associative, a = b = 1 assigns 1 to both
+	p.nextToken()
+	expression.Value = p.parseExpression(ASSIGN - 1)
+
+	return expression
+}
+
+// isConst reports whether the name refers to a constant declared in the innermost scope that declares it
+func (p *Parser) isConst(name string) bool {
+	for i := len(p.scopes) - 1; i >= 0; i-- {
+		if constant, ok := p.scopes[i][name]; ok {
+			return constant
+		}
+	}
+	return false
+}
+
 func (p *Parser) parseBooleanLiteral() ast.Expression {
 	defer untrace(trace("parseBooleanLiteral"))
 
@@ -314,11 +674,12 @@ func (p *Parser) parseBooleanLiteral() ast.Expression {
 func (p *Parser) parseGroupedExpression() ast.Expression {
 	defer untrace(trace("parseGroupedExpression"))
 
+	start := p.curToken
 	p.nextToken()
 
 	exp := p.parseExpression(LOWEST)
 	if !p.expectPeek(token.RPAREN) {
-		return nil
+		return p.badExpression(start)
 	}
 	return exp
 }
@@ -329,33 +690,43 @@ func (p *Parser) parseIfExpression() ast.Expression {
 	expression := &ast.IfExpression{Token: p.curToken}
 
 	if !p.expectPeek(token.LPAREN) {
-		return nil
+		return p.badExpression(expression.Token)
 	}
 
 	p.nextToken()
 	expression.Condition = p.parseExpression(LOWEST)
 
 	if !p.expectPeek(token.RPAREN) {
-		return nil
+		return p.badExpression(expression.Token)
 	}
 
 	if !p.expectPeek(token.LBRACE) {
-		return nil
+		return p.badExpression(expression.Token)
 	}
 
-	expression.Consequence = p.parseBlockStatement()
+	expression.Consequence = p.parseBranch()
 
 	if p.peekTokenIs(token.ELSE) {
 		p.nextToken()
 		if !p.expectPeek(token.LBRACE) {
-			return nil
+			return p.badExpression(expression.Token)
 		}
-		expression.Alternative = p.parseBlockStatement()
+		expression.Alternative = p.parseBranch()
 	}
 
 	return expression
 }
 
+// parseBranch parses a block of an if expression. The block is evaluated in the enclosing scope, but as
+// at most one of the branches runs, each gets a scope of its own so that declarations in one branch do
+// not conflict with those in the other or after the if expression; the evaluator reports real conflicts.
+func (p *Parser) parseBranch() *ast.BlockStatement {
+	p.scopes = append(p.scopes, map[string]bool{})
+	defer func() { p.scopes = p.scopes[:len(p.scopes)-1] }()
+
+	return p.parseBlockStatement()
+}
+
 func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	defer untrace(trace("parseBlockStatement"))
 
@@ -365,33 +736,51 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	p.nextToken()
 
 	for !p.curTokenIs(token.RBRACE) && !p.curTokenIs(token.EOF) {
-		stmt := p.parseStatement()
-		if stmt != nil {
-			block.Statements = append(block.Statements, stmt)
-		}
+		stmt := p.parseStatementWithRecovery()
+		block.Statements = append(block.Statements, stmt)
 		p.nextToken()
 	}
 
+	if p.curTokenIs(token.RBRACE) {
+		block.Rbrace = p.curToken
+	} else {
+		msg := fmt.Sprintf("expected %s to close block, got %s instead", token.RBRACE, p.curToken.Type)
+		p.addError(p.curToken, []token.TokenType{token.RBRACE}, msg)
+	}
+
 	return block
 }
 
 func (p *Parser) parseFunctionLiteral() ast.Expression {
 	defer untrace(trace("parseFunctionLiteral"))
 
-	lit := &ast.FunctionLiteral{}
+	lit := &ast.FunctionLiteral{Token: p.curToken}
 
 	if !p.expectPeek(token.LPAREN) {
-		return nil
+		return p.badExpression(lit.Token)
 	}
 
 	lit.Parameters = p.parseFunctionParameters()
 
 	if !p.expectPeek(token.LBRACE) {
-		return nil
+		return p.badExpression(lit.Token)
 	}
 
+	// the body is evaluated in a new scope where the parameters are variables
+	scope := map[string]bool{}
+	for _, param := range lit.Parameters {
+		scope[param.Value] = false
+	}
+	// a break or continue cannot leave the function, whether or not it is defined inside a loop
+	loopDepth := p.loopDepth
+	p.scopes = append(p.scopes, scope)
+	p.loopDepth = 0
+
 	lit.Body = p.parseBlockStatement()
 
+	p.loopDepth = loopDepth
+	p.scopes = p.scopes[:len(p.scopes)-1]
+
 	return lit
 }
 
@@ -405,21 +794,23 @@ func (p *Parser) parseFunctionParameters() []*ast.Identifier {
 		return identifiers
 	}
 
-	p.nextToken()
+	if !p.expectPeek(token.IDENT) {
+		return identifiers
+	}
 
 	ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 	identifiers = append(identifiers, ident)
 
 	for p.peekTokenIs(token.COMMA) {
 		p.nextToken()
-		p.nextToken()
+		if !p.expectPeek(token.IDENT) {
+			return identifiers
+		}
 		ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 		identifiers = append(identifiers, ident)
 	}
 
-	if !p.expectPeek(token.RPAREN) {
-		return nil
-	}
+	p.expectPeek(token.RPAREN)
 
 	return identifiers
 }
@@ -428,35 +819,37 @@ func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
 	defer untrace(trace("parseCallExpression"))
 
 	exp := &ast.CallExpression{Token: p.curToken, Function: function}
-	exp.Arguments = p.parseCallArguments()
+	exp.Arguments = p.parseExpressionList(token.RPAREN)
+	if !p.panicking {
+		exp.Rparen = p.curToken
+	}
 	return exp
 }
 
-func (p *Parser) parseCallArguments() []ast.Expression {
-	defer untrace(trace("parseCallArguments"))
+// parseExpressionList parses comma separated expressions up to the given closing token
+func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
+	defer untrace(trace("parseExpressionList"))
 
-	args := []ast.Expression{}
+	list := []ast.Expression{}
 
-	if p.peekTokenIs(token.RPAREN) {
+	if p.peekTokenIs(end) {
 		p.nextToken()
-		return args
+		return list
 	}
 
 	p.nextToken()
 
-	args = append(args, p.parseExpression(LOWEST))
+	list = append(list, p.parseExpression(LOWEST))
 
 	for p.peekTokenIs(token.COMMA) {
 		p.nextToken()
 		p.nextToken()
-		args = append(args, p.parseExpression(LOWEST))
+		list = append(list, p.parseExpression(LOWEST))
 	}
 
-	if !p.expectPeek(token.RPAREN) {
-		return nil
-	}
+	p.expectPeek(end)
 
-	return args
+	return list
 }
 
 func (p *Parser) parseStringLiteral() ast.Expression {
@@ -464,3 +857,68 @@ func (p *Parser) parseStringLiteral() ast.Expression {
 
 	return &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
 }
+
+// parseIllegal reports the problem the lexer found, the literal of an illegal token describes it
+func (p *Parser) parseIllegal() ast.Expression {
+	defer untrace(trace("parseIllegal"))
+
+	p.addError(p.curToken, nil, p.curToken.Literal)
+	return p.badExpression(p.curToken)
+}
+
+func (p *Parser) parseArrayLiteral() ast.Expression {
+	defer untrace(trace("parseArrayLiteral"))
+
+	array := &ast.ArrayLiteral{Token: p.curToken}
+	array.Elements = p.parseExpressionList(token.RBRACKET)
+	if !p.panicking {
+		array.Rbracket = p.curToken
+	}
+
+	return array
+}
+
+func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
+	defer untrace(trace("parseIndexExpression"))
+
+	exp := &ast.IndexExpression{Token: p.curToken, Left: left}
+
+	p.nextToken()
+	exp.Index = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RBRACKET) {
+		return p.badExpression(exp.Token)
+	}
+	exp.Rbracket = p.curToken
+
+	return exp
+}
+
+func (p *Parser) parseHashLiteral() ast.Expression {
+	defer untrace(trace("parseHashLiteral"))
+
+	hash := &ast.HashLiteral{Token: p.curToken, Pairs: []ast.HashLiteralPair{}}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+		key := p.parseExpression(LOWEST)
+
+		if !p.expectPeek(token.COLON) {
+			return p.badExpression(hash.Token)
+		}
+
+		p.nextToken()
+		value := p.parseExpression(LOWEST)
+
+		hash.Pairs = append(hash.Pairs, ast.HashLiteralPair{Key: key, Value: value})
+
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return p.badExpression(hash.Token)
+		}
+	}
+
+	p.nextToken()
+	hash.Rbrace = p.curToken
+
+	return hash
+}
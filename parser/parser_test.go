@@ -1,12 +1,15 @@
 package parser_test
 
 import (
+	"bytes"
 	"fmt"
+	"strings"
 	"testing"
 
 	"monkey/ast"
 	"monkey/lexer"
 	"monkey/parser"
+	"monkey/token"
 
 	"github.com/stretchr/testify/suite"
 )
@@ -133,6 +136,89 @@ func (s *Suite) TestIntegerLiteralExpression() {
 	s.Require().Equal("5", intLiteral.TokenLiteral())
 }
 
+func (s *Suite) TestIntegerLiteralForms() {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"0xff", 255},
+		{"0o17", 15},
+		{"0b101", 5},
+		{"1_000", 1000},
+		{"0x_7fff_ffff", 0x7fffffff},
+	}
+
+	for _, tt := range tests {
+		lex := lexer.New(tt.input)
+		p := parser.New(lex)
+		program := p.ParseProgram()
+		s.Require().Empty(p.Errors(), tt.input)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		intLiteral, ok := stmt.Expression.(*ast.IntegerLiteral)
+		s.Require().Truef(ok, "s not *ast.IntegerLiteral. got=%T", stmt.Expression)
+
+		s.Require().Equal(tt.expected, intLiteral.Value, tt.input)
+		// the original spelling is kept for formatting
+		s.Require().Equal(tt.input, intLiteral.String(), tt.input)
+	}
+}
+
+func (s *Suite) TestMalformedIntegerLiterals() {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x = 0x;", "1:9: hexadecimal literal has no digits"},
+		{"let x = 1__0;", "1:9: '_' must separate successive digits"},
+		{"let x = [1, 0b12];", "1:13: invalid digit '2' in binary literal"},
+	}
+
+	for _, tt := range tests {
+		lex := lexer.New(tt.input)
+		p := parser.New(lex)
+		p.ParseProgram()
+
+		s.Require().Equal([]string{tt.expected}, p.Errors(), tt.input)
+	}
+}
+
+func (s *Suite) TestBigIntegerLiteralExpression() {
+	lex := lexer.New("99999999999999999999;")
+	p := parser.New(lex)
+
+	program := p.ParseProgram()
+	s.Require().Len(p.Errors(), 0, "parser had errors")
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	intLiteral, ok := stmt.Expression.(*ast.IntegerLiteral)
+	s.Require().Truef(ok, "s not *ast.IntegerLiteral. got=%T", stmt.Expression)
+
+	s.Require().NotNil(intLiteral.Big)
+	s.Require().Equal("99999999999999999999", intLiteral.Big.String())
+	s.Require().Equal("99999999999999999999", intLiteral.String())
+}
+
+func (s *Suite) TestFloatLiteralExpression() {
+	input := "3.25;"
+
+	lex := lexer.New(input)
+	p := parser.New(lex)
+
+	program := p.ParseProgram()
+	s.Require().Len(p.Errors(), 0, "parser had errors")
+	s.Require().Len(program.Statements, 1)
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	s.Require().Truef(ok, "s not *ast.ExpressionStatement. got=%T", program.Statements[0])
+
+	floatLiteral, ok := stmt.Expression.(*ast.FloatLiteral)
+	s.Require().Truef(ok, "s not *ast.FloatLiteral. got=%T", stmt.Expression)
+
+	s.Require().Equal(3.25, floatLiteral.Value)
+	s.Require().Equal("3.25", floatLiteral.TokenLiteral())
+}
+
 func (s *Suite) TestParsingPrefixExpressions() {
 	prefixTests := []struct {
 		input        string
@@ -315,6 +401,70 @@ func (s *Suite) TestOperatorPrecedenceParsing() {
 			"add(a + b + c * d / f + g)",
 			"add((((a + b) + ((c * d) / f)) + g))",
 		},
+		{
+			"a * [1, 2, 3, 4][b * c] * d",
+			"((a * ([1, 2, 3, 4][(b * c)])) * d)",
+		},
+		{
+			"a <= b == c >= d",
+			"((a <= b) == (c >= d))",
+		},
+		{
+			"a || b && c || d",
+			"((a || (b && c)) || d)",
+		},
+		{
+			"a < b && b < c",
+			"((a < b) && (b < c))",
+		},
+		{
+			"a + b % c",
+			"(a + (b % c))",
+		},
+		{
+			"2 ** 3 ** 2",
+			"(2 ** (3 ** 2))",
+		},
+		{
+			"-2 ** 2",
+			"(-(2 ** 2))",
+		},
+		{
+			"a * b ** c",
+			"(a * (b ** c))",
+		},
+		{
+			"2 ** -1",
+			"(2 ** (-1))",
+		},
+		{
+			"a | b & c ^ d",
+			"((a | (b & c)) ^ d)",
+		},
+		{
+			"1 << 2 + 3 >> 1",
+			"((1 << 2) + (3 >> 1))",
+		},
+		{
+			"a & b == c",
+			"((a & b) == c)",
+		},
+		{
+			"x = y = 1 + 2",
+			"(x = (y = (1 + 2)))",
+		},
+		{
+			"a[i] += b || c",
+			"((a[i]) += (b || c))",
+		},
+		{
+			"x *= -y",
+			"(x *= (-y))",
+		},
+		{
+			"add(a * b[2], b[1], 2 * [1, 2][1])",
+			"add((a * (b[2])), (b[1]), (2 * ([1, 2][1])))",
+		},
 	}
 	for _, tt := range tests {
 		lex := lexer.New(tt.input)
@@ -403,6 +553,112 @@ func (s *Suite) TestIfExpression() {
 	s.Require().Nil(exp.Alternative)
 }
 
+func (s *Suite) TestWhileStatement() {
+	input := `while (x < y) { if (x == 3) { continue; } x += 1; break }`
+
+	lex := lexer.New(input)
+	p := parser.New(lex)
+	program := p.ParseProgram()
+
+	s.Require().Empty(p.Errors())
+	s.Require().Len(program.Statements, 1)
+
+	stmt, ok := program.Statements[0].(*ast.WhileStatement)
+	s.Require().Truef(ok, "s not *ast.WhileStatement. got=%T", program.Statements[0])
+
+	testInfixExpression(s, stmt.Condition, "x", "<", "y")
+
+	s.Require().Len(stmt.Body.Statements, 3)
+	s.Require().IsType(&ast.BreakStatement{}, stmt.Body.Statements[2])
+	s.Require().Equal("while(x < y) if(x == 3) continue;(x += 1)break;", stmt.String())
+	s.Require().Equal("1:58", stmt.End().String())
+}
+
+func (s *Suite) TestForStatement() {
+	tests := []struct {
+		input    string
+		key      string
+		value    string
+		iterable string
+		expected string
+	}{
+		{"for (x in xs) { puts(x); }", "", "x", "xs", "for(x in xs) puts(x)"},
+		{"for (k, v in {1: 2}) { k + v }", "k", "v", "{1: 2}", "for(k, v in {1: 2}) (k + v)"},
+		{"for (i in range(0, 10, 2)) { if (i > 4) { break } }", "", "i", "range(0, 10, 2)", "for(i in range(0, 10, 2)) if(i > 4) break;"},
+	}
+
+	for _, tt := range tests {
+		lex := lexer.New(tt.input)
+		p := parser.New(lex)
+		program := p.ParseProgram()
+
+		s.Require().Empty(p.Errors(), tt.input)
+		s.Require().Len(program.Statements, 1)
+
+		stmt, ok := program.Statements[0].(*ast.ForStatement)
+		s.Require().Truef(ok, "s not *ast.ForStatement. got=%T", program.Statements[0])
+
+		if tt.key == "" {
+			s.Require().Nil(stmt.Key)
+		} else {
+			testIdentifier(s, stmt.Key, tt.key)
+		}
+		testIdentifier(s, stmt.Value, tt.value)
+		s.Require().Equal(tt.iterable, stmt.Iterable.String())
+		s.Require().Equal(tt.expected, stmt.String())
+		s.Require().Equal(stmt.Body.End(), stmt.End())
+	}
+}
+
+func (s *Suite) TestForStatementErrors() {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"for (x of xs) { }", []string{"1:8: expected next token to be IN, got IDENT instead"}},
+		{"for (1 in xs) { }", []string{"1:6: expected next token to be IDENT, got INT instead"}},
+		{"const x = 1; for (x in xs) { x = 2; }", nil},
+		{"for (x in xs) { continue; } x = 1; break;", []string{"1:36: break outside loop"}},
+	}
+
+	for _, tt := range tests {
+		lex := lexer.New(tt.input)
+		p := parser.New(lex)
+		p.ParseProgram()
+
+		if tt.expected == nil {
+			s.Require().Empty(p.Errors(), tt.input)
+		} else {
+			s.Require().Equal(tt.expected, p.Errors(), tt.input)
+		}
+	}
+}
+
+func (s *Suite) TestLoopControlOutsideLoop() {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"break;", []string{"1:1: break outside loop"}},
+		{"if (true) { continue }", []string{"1:13: continue outside loop"}},
+		{"while (true) { fn() { break; } }", []string{"1:23: break outside loop"}},
+		{"while (true) { break; } continue; let x = 1;", []string{"1:25: continue outside loop"}},
+		{"while (true) { fn() { while (true) { break } } }", nil},
+	}
+
+	for _, tt := range tests {
+		lex := lexer.New(tt.input)
+		p := parser.New(lex)
+		p.ParseProgram()
+
+		if tt.expected == nil {
+			s.Require().Empty(p.Errors(), tt.input)
+		} else {
+			s.Require().Equal(tt.expected, p.Errors(), tt.input)
+		}
+	}
+}
+
 func (s *Suite) TestIfElseExpression() {
 	input := `if (x < y) { x } else { y }`
 
@@ -543,3 +799,379 @@ func (s *Suite) TestStringLiteralExpression() {
 
 	s.Require().Equal("hello world", sl.Value)
 }
+
+func (s *Suite) TestNodePositions() {
+	input := "let add = fn(x, y) {\n  x + y;\n};\nadd(1, 2)"
+
+	lex := lexer.New(input)
+	p := parser.New(lex)
+	program := p.ParseProgram()
+
+	s.Require().Len(p.Errors(), 0)
+	s.Require().Len(program.Statements, 2)
+
+	let := program.Statements[0].(*ast.LetStatement)
+	s.Require().Equal("1:1", let.Pos().String())
+	s.Require().Equal("3:2", let.End().String())
+
+	function := let.Value.(*ast.FunctionLiteral)
+	s.Require().Equal("1:11", function.Pos().String())
+
+	body := function.Body.Statements[0].(*ast.ExpressionStatement)
+	s.Require().Equal("2:3", body.Pos().String())
+	s.Require().Equal("2:8", body.End().String())
+
+	call := program.Statements[1].(*ast.ExpressionStatement).Expression.(*ast.CallExpression)
+	s.Require().Equal("4:1", call.Pos().String())
+	s.Require().Equal("4:10", call.End().String())
+
+	s.Require().Equal("1:1", program.Pos().String())
+	s.Require().Equal("4:10", program.End().String())
+}
+
+func (s *Suite) TestParseErrors() {
+	input := "let x = 5;\nlet = 10;"
+
+	lex := lexer.NewWithFilename("test.mk", input)
+	p := parser.New(lex)
+	p.ParseProgram()
+
+	errs := p.ParseErrors()
+	s.Require().NotEmpty(errs)
+
+	err := errs[0]
+	s.Require().Equal("test.mk:2:5", err.Pos.String())
+	s.Require().Equal([]token.TokenType{token.IDENT}, err.Expected)
+	s.Require().Equal(token.TokenType(token.ASSIGN), err.Actual.Type)
+	s.Require().Equal("test.mk:2:5: expected next token to be IDENT, got = instead", err.Error())
+	s.Require().Equal(err.Error(), p.Errors()[0])
+}
+
+func (s *Suite) TestRenderErrors() {
+	input := "let x = 5;\n\tlet y = (1 + foo;"
+
+	lex := lexer.New(input)
+	p := parser.New(lex)
+	p.ParseProgram()
+
+	s.Require().NotEmpty(p.ParseErrors())
+
+	var out bytes.Buffer
+	parser.RenderErrors(&out, input, p.ParseErrors()[:1])
+
+	expected := "2:18: expected next token to be ), got ; instead\n" +
+		"   2 | \tlet y = (1 + foo;\n" +
+		"     | \t                ^\n"
+	s.Require().Equal(expected, out.String())
+}
+
+func (s *Suite) TestRenderErrorsUnicode() {
+	input := `let größe = "é" + ;`
+
+	lex := lexer.New(input)
+	p := parser.New(lex)
+	p.ParseProgram()
+
+	s.Require().NotEmpty(p.ParseErrors())
+
+	var out bytes.Buffer
+	parser.RenderErrors(&out, input, p.ParseErrors()[:1])
+
+	expected := "1:19: no prefix parse function for ; found\n" +
+		"   1 | let größe = \"é\" + ;\n" +
+		"     |                   ^\n"
+	s.Require().Equal(expected, out.String())
+}
+
+func (s *Suite) TestErrorRecovery() {
+	input := `
+let = 5;
+let y = 10;
+if (y { y };
+let z = add(1, 2;
+z;
+`
+
+	lex := lexer.New(input)
+	p := parser.New(lex)
+	program := p.ParseProgram()
+
+	s.Require().Equal([]string{
+		"2:5: expected next token to be IDENT, got = instead",
+		"4:7: expected next token to be ), got { instead",
+		"5:17: expected next token to be ), got ; instead",
+	}, p.Errors())
+
+	s.Require().Len(program.Statements, 5)
+
+	_, ok := program.Statements[0].(*ast.BadStatement)
+	s.Require().Truef(ok, "s not *ast.BadStatement. got=%T", program.Statements[0])
+	testLetStatement(s, program.Statements[1], "y")
+	_, ok = program.Statements[2].(*ast.BadStatement)
+	s.Require().Truef(ok, "s not *ast.BadStatement. got=%T", program.Statements[2])
+	_, ok = program.Statements[3].(*ast.BadStatement)
+	s.Require().Truef(ok, "s not *ast.BadStatement. got=%T", program.Statements[3])
+
+	stmt, ok := program.Statements[4].(*ast.ExpressionStatement)
+	s.Require().Truef(ok, "s not *ast.ExpressionStatement. got=%T", program.Statements[4])
+	testIdentifier(s, stmt.Expression, "z")
+}
+
+func (s *Suite) TestErrorRecoveryInBlock() {
+	input := `let f = fn(x) { let = 1; let h = {"a" 1}; x };`
+
+	lex := lexer.New(input)
+	p := parser.New(lex)
+	program := p.ParseProgram()
+
+	s.Require().Len(p.Errors(), 2)
+	s.Require().Len(program.Statements, 1)
+
+	let, ok := program.Statements[0].(*ast.LetStatement)
+	s.Require().Truef(ok, "s not *ast.LetStatement. got=%T", program.Statements[0])
+
+	function := let.Value.(*ast.FunctionLiteral)
+	s.Require().Len(function.Body.Statements, 3)
+
+	_, ok = function.Body.Statements[0].(*ast.BadStatement)
+	s.Require().Truef(ok, "s not *ast.BadStatement. got=%T", function.Body.Statements[0])
+}
+
+func (s *Suite) TestUnclosedBlock() {
+	lex := lexer.New(`fn(x) { x`)
+	p := parser.New(lex)
+	p.ParseProgram()
+
+	s.Require().Equal([]string{"1:10: expected } to close block, got EOF instead"}, p.Errors())
+}
+
+func (s *Suite) TestErrorLimit() {
+	input := strings.Repeat("let = 1;\n", 20)
+
+	lex := lexer.New(input)
+	p := parser.New(lex)
+	p.ParseProgram()
+
+	errs := p.Errors()
+	s.Require().Len(errs, 11)
+	s.Require().Equal("11:5: too many errors", errs[10])
+}
+
+func (s *Suite) TestCommentsAreSkipped() {
+	input := "let x = 1; // one\n/* two */ let y = x /* three */ + 2;"
+
+	lex := lexer.New(input)
+	lex.SetMode(lexer.ScanComments)
+	p := parser.New(lex)
+	program := p.ParseProgram()
+
+	s.Require().Empty(p.Errors())
+	s.Require().Equal("let x = 1;let y = (x + 2);", program.String())
+}
+
+func (s *Suite) TestIllegalTokens() {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x = @;", "1:9: unexpected character '@'"},
+		{"let x = 1 + /* 2;", "1:13: unterminated block comment"},
+		{`let x = "a\qb";`, `1:9: unknown escape sequence \q`},
+		{`let x = "abc`, "1:9: unterminated string literal"},
+	}
+
+	for _, tt := range tests {
+		lex := lexer.New(tt.input)
+		p := parser.New(lex)
+		p.ParseProgram()
+
+		s.Require().Equal([]string{tt.expected}, p.Errors(), tt.input)
+	}
+}
+
+func (s *Suite) TestAssignExpression() {
+	lex := lexer.New("counter /= 2;")
+	p := parser.New(lex)
+	program := p.ParseProgram()
+	s.Require().Empty(p.Errors())
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	assign, ok := stmt.Expression.(*ast.AssignExpression)
+	s.Require().Truef(ok, "not *ast.AssignExpression. got=%T", stmt.Expression)
+
+	s.Require().Equal("/=", assign.Operator)
+	s.Require().Equal("counter", assign.Target.String())
+	s.Require().Equal("2", assign.Value.String())
+	s.Require().Equal("1:1", assign.Pos().String())
+	s.Require().Equal("1:13", assign.End().String())
+}
+
+func (s *Suite) TestInvalidAssignmentTarget() {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1 = 2;", "1:1: cannot assign to 1"},
+		{"let x = 1; f(x) += 1;", "1:12: cannot assign to f(x)"},
+	}
+
+	for _, tt := range tests {
+		lex := lexer.New(tt.input)
+		p := parser.New(lex)
+		p.ParseProgram()
+
+		s.Require().Equal([]string{tt.expected}, p.Errors(), tt.input)
+	}
+}
+
+func (s *Suite) TestConstStatement() {
+	lex := lexer.New("const limit = 10;")
+	p := parser.New(lex)
+	program := p.ParseProgram()
+	s.Require().Empty(p.Errors())
+
+	stmt, ok := program.Statements[0].(*ast.LetStatement)
+	s.Require().Truef(ok, "not *ast.LetStatement. got=%T", program.Statements[0])
+	s.Require().True(stmt.IsConst())
+	s.Require().Equal("const limit = 10;", stmt.String())
+}
+
+func (s *Suite) TestConstantErrors() {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"const x = 1; x = 2;", []string{"1:14: cannot assign to constant x"}},
+		{"const x = 1; x += 2;", []string{"1:14: cannot assign to constant x"}},
+		{"const x = 1; let x = 2;", []string{"1:18: cannot redeclare constant x"}},
+		{"const x = 1; let f = fn() { x = 2; };", []string{"1:29: cannot assign to constant x"}},
+		{"const x = 1; const x = 2; x = 3;", []string{"1:20: cannot redeclare constant x", "1:27: cannot assign to constant x"}},
+		{"const x = 1; let f = fn() { let x = 2; x = 3; };", nil},
+		{"const x = 1; let f = fn(x) { x = 2; };", nil},
+		{"let x = 1; const x = 2;", nil},
+		{"let a = true; if (a) { const x = 1 } else { const x = 2 }; x", nil},
+		{"if (true) { const x = 1; x = 2; }", []string{"1:26: cannot assign to constant x"}},
+	}
+
+	for _, tt := range tests {
+		lex := lexer.New(tt.input)
+		p := parser.New(lex)
+		p.ParseProgram()
+
+		if tt.expected == nil {
+			s.Require().Empty(p.Errors(), tt.input)
+		} else {
+			s.Require().Equal(tt.expected, p.Errors(), tt.input)
+		}
+	}
+}
+
+func (s *Suite) TestParsingArrayLiterals() {
+	input := "[1, 2 * 2, 3 + 3]"
+
+	lex := lexer.New(input)
+	p := parser.New(lex)
+	program := p.ParseProgram()
+
+	s.Require().Len(p.Errors(), 0)
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	s.Require().Truef(ok, "s not *ast.ExpressionStatement. got=%T", program.Statements[0])
+
+	array, ok := stmt.Expression.(*ast.ArrayLiteral)
+	s.Require().Truef(ok, "exp not *ast.ArrayLiteral. got=%T", stmt.Expression)
+
+	s.Require().Len(array.Elements, 3)
+
+	testIntegerLiteral(s, array.Elements[0], 1)
+	testInfixExpression(s, array.Elements[1], 2, "*", 2)
+	testInfixExpression(s, array.Elements[2], 3, "+", 3)
+
+	s.Require().Equal("1:1", array.Pos().String())
+	s.Require().Equal("1:18", array.End().String())
+}
+
+func (s *Suite) TestParsingEmptyArrayLiterals() {
+	input := "[]"
+
+	lex := lexer.New(input)
+	p := parser.New(lex)
+	program := p.ParseProgram()
+
+	s.Require().Len(p.Errors(), 0)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	array, ok := stmt.Expression.(*ast.ArrayLiteral)
+	s.Require().Truef(ok, "exp not *ast.ArrayLiteral. got=%T", stmt.Expression)
+
+	s.Require().Len(array.Elements, 0)
+}
+
+func (s *Suite) TestParsingIndexExpressions() {
+	input := "myArray[1 + 1]"
+
+	lex := lexer.New(input)
+	p := parser.New(lex)
+	program := p.ParseProgram()
+
+	s.Require().Len(p.Errors(), 0)
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	s.Require().Truef(ok, "s not *ast.ExpressionStatement. got=%T", program.Statements[0])
+
+	indexExp, ok := stmt.Expression.(*ast.IndexExpression)
+	s.Require().Truef(ok, "exp not *ast.IndexExpression. got=%T", stmt.Expression)
+
+	testIdentifier(s, indexExp.Left, "myArray")
+	testInfixExpression(s, indexExp.Index, 1, "+", 1)
+}
+
+func (s *Suite) TestParsingHashLiterals() {
+	input := `{"one": 1, 2: "two", true: 3 + 3}`
+
+	lex := lexer.New(input)
+	p := parser.New(lex)
+	program := p.ParseProgram()
+
+	s.Require().Len(p.Errors(), 0)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	hash, ok := stmt.Expression.(*ast.HashLiteral)
+	s.Require().Truef(ok, "exp not *ast.HashLiteral. got=%T", stmt.Expression)
+
+	s.Require().Len(hash.Pairs, 3)
+
+	key, ok := hash.Pairs[0].Key.(*ast.StringLiteral)
+	s.Require().Truef(ok, "key not *ast.StringLiteral. got=%T", hash.Pairs[0].Key)
+	s.Require().Equal("one", key.Value)
+	testIntegerLiteral(s, hash.Pairs[0].Value, 1)
+
+	testIntegerLiteral(s, hash.Pairs[1].Key, 2)
+	testBooleanLiteral(s, hash.Pairs[2].Key, true)
+	testInfixExpression(s, hash.Pairs[2].Value, 3, "+", 3)
+
+	s.Require().Equal(`{one: 1, 2: two, true: (3 + 3)}`, hash.String())
+	s.Require().Equal("1:34", hash.End().String())
+}
+
+func (s *Suite) TestParsingEmptyHashLiteral() {
+	lex := lexer.New("{}")
+	p := parser.New(lex)
+	program := p.ParseProgram()
+
+	s.Require().Len(p.Errors(), 0)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	hash, ok := stmt.Expression.(*ast.HashLiteral)
+	s.Require().Truef(ok, "exp not *ast.HashLiteral. got=%T", stmt.Expression)
+
+	s.Require().Len(hash.Pairs, 0)
+}
+
+func (s *Suite) TestParsingInvalidHashLiteral() {
+	lex := lexer.New(`{"one" 1}`)
+	p := parser.New(lex)
+	p.ParseProgram()
+
+	s.Require().Equal([]string{"1:8: expected next token to be :, got INT instead"}, p.Errors())
+}
@@ -0,0 +1,122 @@
+package repl
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/token"
+)
+
+// ANSI escape sequences used for highlighting
+const (
+	colorReset   = "\x1b[0m"
+	colorRed     = "\x1b[31m"
+	colorGreen   = "\x1b[32m"
+	colorYellow  = "\x1b[33m"
+	colorBlue    = "\x1b[34m"
+	colorMagenta = "\x1b[35m"
+	colorCyan    = "\x1b[36m"
+	colorGray    = "\x1b[90m"
+)
+
+var tokenColors = map[token.TokenType]string{
+	token.FUNCTION: colorMagenta,
+	token.LET:      colorMagenta,
+	token.CONST:    colorMagenta,
+	token.IF:       colorMagenta,
+	token.ELSE:     colorMagenta,
+	token.RETURN:   colorMagenta,
+	token.WHILE:    colorMagenta,
+	token.BREAK:    colorMagenta,
+	token.CONTINUE: colorMagenta,
+	token.FOR:      colorMagenta,
+	token.IN:       colorMagenta,
+	token.TRUE:     colorBlue,
+	token.FALSE:    colorBlue,
+	token.INT:      colorYellow,
+	token.FLOAT:    colorYellow,
+	token.STRING:   colorGreen,
+	token.ILLEGAL:  colorRed,
+	token.COMMENT:  colorGray,
+	token.ASSIGN:   colorCyan,
+	token.PLUS:     colorCyan,
+	token.MINUS:    colorCyan,
+	token.BANG:     colorCyan,
+	token.ASTERISK: colorCyan,
+	token.SLASH:    colorCyan,
+	token.LT:       colorCyan,
+	token.GT:       colorCyan,
+	token.EQ:       colorCyan,
+	token.NOT_EQ:   colorCyan,
+
+	token.PERCENT:     colorCyan,
+	token.POWER:       colorCyan,
+	token.LT_EQ:       colorCyan,
+	token.GT_EQ:       colorCyan,
+	token.AND:         colorCyan,
+	token.OR:          colorCyan,
+	token.AMPERSAND:   colorCyan,
+	token.PIPE:        colorCyan,
+	token.CARET:       colorCyan,
+	token.SHIFT_LEFT:  colorCyan,
+	token.SHIFT_RIGHT: colorCyan,
+
+	token.PLUS_ASSIGN:     colorCyan,
+	token.MINUS_ASSIGN:    colorCyan,
+	token.ASTERISK_ASSIGN: colorCyan,
+	token.SLASH_ASSIGN:    colorCyan,
+}
+
+var objectColors = map[object.ObjectType]string{
+	object.INTEGER_OBJ:  colorYellow,
+	object.FLOAT_OBJ:    colorYellow,
+	object.BIGINT_OBJ:   colorYellow,
+	object.STRING_OBJ:   colorGreen,
+	object.BOOLEAN_OBJ:  colorBlue,
+	object.NULL_OBJ:     colorGray,
+	object.FUNCTION_OBJ: colorMagenta,
+	object.BUILTIN_OBJ:  colorMagenta,
+	object.ERROR_OBJ:    colorRed,
+}
+
+// ColorEnabled reports whether output written to out should be colored: out must be a terminal and
+// the NO_COLOR environment variable must not be set
+func ColorEnabled(out io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	file, ok := out.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// highlight colors the source by the types of its tokens, the text in between tokens is kept as it is
+func highlight(src string) string {
+	var out strings.Builder
+
+	lex := lexer.New(src)
+	lex.SetMode(lexer.ScanComments)
+	offset := 0
+	for tok := lex.NextToken(); tok.Type != token.EOF; tok = lex.NextToken() {
+		start, end := tok.Pos.Offset, tok.End.Offset
+		out.WriteString(src[offset:start])
+		out.WriteString(colorize(tokenColors[tok.Type], src[start:end]))
+		offset = end
+	}
+	out.WriteString(src[offset:])
+
+	return out.String()
+}
+
+func colorize(color, s string) string {
+	if color == "" || s == "" {
+		return s
+	}
+	return color + s + colorReset
+}
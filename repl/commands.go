@@ -0,0 +1,140 @@
+package repl
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"monkey/ast"
+	"monkey/evaluator"
+	"monkey/lexer"
+	"monkey/object"
+	"monkey/parser"
+	"monkey/token"
+)
+
+// COMMAND_PREFIX starts a line holding a REPL command instead of Monkey code
+const COMMAND_PREFIX = ":"
+
+type command struct {
+	name  string
+	usage string
+	help  string
+	run   func(s *session, arg string)
+}
+
+// commands lists the REPL commands in the order they are shown by :help
+var commands []command
+
+func init() {
+	commands = []command{
+		{"tokens", ":tokens <source>", "print the tokens of the source", (*session).tokensCommand},
+		{"ast", ":ast <source>", "print the syntax tree of the source", (*session).astCommand},
+		{"type", ":type <expression>", "evaluate the expression and print the type of the result", (*session).typeCommand},
+		{"env", ":env", "list the global bindings", (*session).envCommand},
+		{"load", ":load <file>", "evaluate a script file in the current environment", (*session).loadCommand},
+		{"reset", ":reset", "discard all bindings", (*session).resetCommand},
+		{"help", ":help", "show this help", (*session).helpCommand},
+	}
+}
+
+func isCommand(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), COMMAND_PREFIX)
+}
+
+// runCommand executes a line of the form ":name argument"
+func (s *session) runCommand(line string) {
+	line = strings.TrimPrefix(strings.TrimSpace(line), COMMAND_PREFIX)
+	name, arg, _ := strings.Cut(line, " ")
+	arg = strings.TrimSpace(arg)
+
+	for _, cmd := range commands {
+		if cmd.name == name {
+			cmd.run(s, arg)
+			return
+		}
+	}
+
+	fmt.Fprintf(s.out, "unknown command %s%s, type :help for a list of commands\n", COMMAND_PREFIX, name)
+}
+
+func (s *session) tokensCommand(src string) {
+	lex := lexer.New(src)
+	for tok := lex.NextToken(); ; tok = lex.NextToken() {
+		fmt.Fprintf(s.out, "%-6s %-10s %q\n", tok.Pos, tok.Type, tok.Literal)
+		if tok.Type == token.EOF {
+			return
+		}
+	}
+}
+
+func (s *session) astCommand(src string) {
+	p := parser.New(lexer.New(src))
+	program := p.ParseProgram()
+	if len(p.ParseErrors()) != 0 {
+		printParserErrors(s.out, src, p.ParseErrors())
+		return
+	}
+	ast.Fprint(s.out, program)
+}
+
+func (s *session) typeCommand(src string) {
+	evaluated, ok := s.eval(lexer.New(src), src)
+	if !ok {
+		return
+	}
+	if evaluated == nil {
+		evaluated = evaluator.NULL
+	}
+	fmt.Fprintln(s.out, evaluated.Type())
+}
+
+func (s *session) envCommand(string) {
+	for _, name := range s.env.Names() {
+		value, _ := s.env.Get(name)
+		fmt.Fprintf(s.out, "%s: %s = %s\n", name, value.Type(), firstLine(value.Inspect()))
+	}
+}
+
+func (s *session) loadCommand(path string) {
+	if path == "" {
+		fmt.Fprintln(s.out, "usage: :load <file>")
+		return
+	}
+
+	src, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintln(s.out, err)
+		return
+	}
+
+	evaluated, ok := s.eval(lexer.NewWithFilename(path, string(src)), string(src))
+	if !ok {
+		return
+	}
+	if evaluated != nil && evaluated.Type() == object.ERROR_OBJ {
+		fmt.Fprintln(s.out, evaluated.Inspect())
+		return
+	}
+	fmt.Fprintf(s.out, "loaded %s\n", path)
+}
+
+func (s *session) resetCommand(string) {
+	s.env = s.newEnvironment()
+}
+
+func (s *session) helpCommand(string) {
+	for _, cmd := range commands {
+		fmt.Fprintf(s.out, "  %-20s %s\n", cmd.usage, cmd.help)
+	}
+	io.WriteString(s.out, "Any other input is evaluated as Monkey code.\n")
+}
+
+// firstLine shortens multi-line values such as functions to their first line
+func firstLine(s string) string {
+	if line, _, found := strings.Cut(s, "\n"); found {
+		return line + " ..."
+	}
+	return s
+}
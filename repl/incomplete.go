@@ -0,0 +1,44 @@
+package repl
+
+import (
+	"monkey/lexer"
+	"monkey/token"
+)
+
+// isIncomplete reports whether the source ends in the middle of a statement: it has unclosed
+// parentheses, brackets or braces, an unterminated string or block comment, or ends with an
+// operator or keyword that needs something to follow it
+func isIncomplete(src string) bool {
+	lex := lexer.New(src)
+	depth := 0
+	last := token.Token{Type: token.EOF}
+
+	for tok := lex.NextToken(); tok.Type != token.EOF; tok = lex.NextToken() {
+		switch tok.Type {
+		case token.LPAREN, token.LBRACKET, token.LBRACE:
+			depth += 1
+		case token.RPAREN, token.RBRACKET, token.RBRACE:
+			depth -= 1
+		case token.ILLEGAL:
+			if tok.Literal == lexer.ErrUnterminatedComment || tok.Literal == lexer.ErrUnterminatedString {
+				return true
+			}
+		}
+		last = tok
+	}
+
+	if depth > 0 {
+		return true
+	}
+
+	switch last.Type {
+	case token.ASSIGN, token.PLUS_ASSIGN, token.MINUS_ASSIGN, token.ASTERISK_ASSIGN, token.SLASH_ASSIGN, token.PLUS, token.MINUS, token.BANG, token.ASTERISK, token.SLASH,
+		token.PERCENT, token.POWER, token.LT, token.GT, token.LT_EQ, token.GT_EQ, token.EQ, token.NOT_EQ,
+		token.AND, token.OR, token.AMPERSAND, token.PIPE, token.CARET, token.SHIFT_LEFT, token.SHIFT_RIGHT,
+		token.COMMA, token.COLON,
+		token.LET, token.CONST, token.FUNCTION, token.IF, token.ELSE, token.WHILE, token.FOR, token.IN:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,105 @@
+package repl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"monkey/evaluator"
+	"monkey/lineedit"
+	"monkey/token"
+)
+
+// HISTORY_FILE is the name of the file in the home directory the REPL history is kept in
+const HISTORY_FILE = ".monkey_history"
+
+// lineReader reads the input of the REPL line by line
+type lineReader interface {
+	// ReadLine shows the prompt and returns the next line, io.EOF ends the session and
+	// lineedit.ErrInterrupted discards the pending input
+	ReadLine(prompt string) (string, error)
+	AddHistory(line string)
+	Close() error
+}
+
+// newLineReader uses a line editor if the input is a terminal and falls back to plain line scanning
+func newLineReader(in io.Reader, out io.Writer, s *session) lineReader {
+	if file, ok := in.(*os.File); ok {
+		if editor, err := lineedit.New(file, out); err == nil {
+			editor.Complete = s.complete
+			if s.options.Color {
+				editor.Highlight = highlight
+			}
+			if home, err := os.UserHomeDir(); err == nil {
+				// the REPL works without history if the file cannot be used
+				editor.OpenHistory(filepath.Join(home, HISTORY_FILE))
+			}
+			return editor
+		}
+	}
+
+	return &scannerReader{scanner: bufio.NewScanner(in), out: out}
+}
+
+// scannerReader reads lines from input that is not a terminal
+type scannerReader struct {
+	scanner *bufio.Scanner
+	out     io.Writer
+}
+
+func (r *scannerReader) ReadLine(prompt string) (string, error) {
+	fmt.Fprint(r.out, prompt)
+
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	return r.scanner.Text(), nil
+}
+
+func (r *scannerReader) AddHistory(string) {}
+func (r *scannerReader) Close() error      { return nil }
+
+// complete offers REPL commands at the start of the line, and key words, builtins and bound names
+// for the identifier before the cursor
+func (s *session) complete(line []rune, pos int) ([]string, int) {
+	start := pos
+	for start > 0 && isIdentifierRune(line[start-1]) {
+		start--
+	}
+	prefix := string(line[start:pos])
+
+	var words []string
+	if start == 1 && line[0] == ':' {
+		for _, cmd := range commands {
+			words = append(words, cmd.name)
+		}
+	} else {
+		words = append(words, token.Keywords()...)
+		words = append(words, evaluator.BuiltinNames()...)
+		words = append(words, s.env.Names()...)
+	}
+
+	candidates := []string{}
+	seen := map[string]bool{}
+	for _, word := range words {
+		if strings.HasPrefix(word, prefix) && !seen[word] {
+			seen[word] = true
+			candidates = append(candidates, word)
+		}
+	}
+	sort.Strings(candidates)
+
+	return candidates, start
+}
+
+func isIdentifierRune(ch rune) bool {
+	return unicode.IsLetter(ch) || unicode.IsDigit(ch) || ch == '_'
+}
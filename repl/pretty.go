@@ -0,0 +1,111 @@
+package repl
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"monkey/object"
+)
+
+// MAX_WIDTH is the width up to which arrays and hashes are printed on a single line
+const MAX_WIDTH = 80
+
+// INDENT is used once per nesting level when arrays and hashes are split over multiple lines
+const INDENT = "  "
+
+// printer formats values for display in the REPL
+type printer struct {
+	color bool
+	width int
+	// visiting holds the arrays and hashes being written, which are printed as [...] and {...} when
+	// they contain themselves
+	visiting map[object.Object]bool
+}
+
+// format returns the value as Inspect would, except that strings nested in arrays and hashes are quoted
+// and arrays and hashes which do not fit the width are printed with one element per line
+func (p *printer) format(obj object.Object) string {
+	var out strings.Builder
+	p.write(&out, obj, 0, false)
+	return out.String()
+}
+
+func (p *printer) write(out *strings.Builder, obj object.Object, level int, nested bool) {
+	switch obj := obj.(type) {
+	case *object.Array:
+		if !p.enter(obj) {
+			out.WriteString("[...]")
+			return
+		}
+		defer p.leave(obj)
+		p.writeCollection(out, obj, "[", "]", level, len(obj.Elements), func(out *strings.Builder, i int, level int) {
+			p.write(out, obj.Elements[i], level, true)
+		})
+	case *object.Hash:
+		if !p.enter(obj) {
+			out.WriteString("{...}")
+			return
+		}
+		defer p.leave(obj)
+		pairs := obj.Pairs()
+		p.writeCollection(out, obj, "{", "}", level, len(pairs), func(out *strings.Builder, i int, level int) {
+			p.write(out, pairs[i].Key, level, true)
+			out.WriteString(": ")
+			p.write(out, pairs[i].Value, level, true)
+		})
+	case *object.String:
+		if nested {
+			out.WriteString(p.colorize(obj.Type(), fmt.Sprintf("%q", obj.Value)))
+		} else {
+			out.WriteString(p.colorize(obj.Type(), obj.Value))
+		}
+	default:
+		out.WriteString(p.colorize(obj.Type(), obj.Inspect()))
+	}
+}
+
+// enter marks the array or hash as being written, it reports false if it already is
+func (p *printer) enter(obj object.Object) bool {
+	if p.visiting[obj] {
+		return false
+	}
+	if p.visiting == nil {
+		p.visiting = map[object.Object]bool{}
+	}
+	p.visiting[obj] = true
+	return true
+}
+
+func (p *printer) leave(obj object.Object) { delete(p.visiting, obj) }
+
+// writeCollection writes the elements on a single line if that fits the width and one per line otherwise
+func (p *printer) writeCollection(out *strings.Builder, obj object.Object, open, close string, level, length int, element func(out *strings.Builder, i int, level int)) {
+	flat := &printer{width: -1}
+	if p.width < 0 || length == 0 || len(INDENT)*level+utf8.RuneCountInString(flat.format(obj)) <= p.width {
+		out.WriteString(open)
+		for i := 0; i < length; i++ {
+			if i > 0 {
+				out.WriteString(", ")
+			}
+			element(out, i, level)
+		}
+		out.WriteString(close)
+		return
+	}
+
+	out.WriteString(open + "\n")
+	for i := 0; i < length; i++ {
+		out.WriteString(strings.Repeat(INDENT, level+1))
+		element(out, i, level+1)
+		out.WriteString(",\n")
+	}
+	out.WriteString(strings.Repeat(INDENT, level) + close)
+}
+
+func (p *printer) colorize(typ object.ObjectType, s string) string {
+	if !p.color {
+		return s
+	}
+	return colorize(objectColors[typ], s)
+}
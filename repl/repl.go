@@ -1,18 +1,22 @@
 package repl
 
 import (
-	"bufio"
-	"fmt"
+	"errors"
 	"io"
+	"strings"
 
 	"monkey/evaluator"
 	"monkey/lexer"
+	"monkey/lineedit"
 	"monkey/object"
 	"monkey/parser"
 )
 
 const PROMPT = ">>"
 
+// CONTINUATION_PROMPT is shown while the input entered so far is incomplete
+const CONTINUATION_PROMPT = ".."
+
 const MONKEY_FACE = `            __,__
    .--.  .-"     "-.  .--.
   / .. \/  .-. .-.  \/ .. \
@@ -26,41 +30,106 @@ const MONKEY_FACE = `            __,__
            '-----'
 `
 
+// Options configure the REPL
+type Options struct {
+	// Color enables syntax highlighting of the input and colored results
+	Color bool
+}
+
+// session holds the state of a running REPL
+type session struct {
+	out     io.Writer
+	env     *object.Environment
+	options Options
+	printer *printer
+}
+
+// Start runs the REPL until the input ends, output is colored if ColorEnabled allows it
 func Start(in io.Reader, out io.Writer) {
-	scanner := bufio.NewScanner(in)
-	env := object.NewEnvironment()
+	StartWithOptions(in, out, Options{Color: ColorEnabled(out)})
+}
+
+// StartWithOptions runs the REPL until the input ends. When in is a terminal lines are read with a line
+// editor providing history and completion, otherwise they are read as they are.
+func StartWithOptions(in io.Reader, out io.Writer, options Options) {
+	s := &session{
+		out:     out,
+		options: options,
+		printer: &printer{color: options.Color, width: MAX_WIDTH},
+	}
+	s.env = s.newEnvironment()
+	reader := newLineReader(in, out, s)
+	defer reader.Close()
+
+	var input strings.Builder
 
 	for {
-		fmt.Fprint(out, PROMPT)
-		scanned := scanner.Scan()
+		prompt := PROMPT
+		if input.Len() > 0 {
+			prompt = CONTINUATION_PROMPT
+		}
 
-		if !scanned {
+		line, err := reader.ReadLine(prompt)
+		if errors.Is(err, lineedit.ErrInterrupted) {
+			input.Reset()
+			continue
+		}
+		if err != nil {
 			return
 		}
+		reader.AddHistory(line)
 
-		line := scanner.Text()
-		lex := lexer.New(line)
-		p := parser.New(lex)
-
-		program := p.ParseProgram()
-		if len(p.Errors()) != 0 {
-			printParserErrors(out, p.Errors())
+		if input.Len() == 0 && isCommand(line) {
+			s.runCommand(line)
 			continue
 		}
 
-		evaluated := evaluator.Eval(program, env)
-		if evaluated != nil {
-			io.WriteString(out, evaluated.Inspect())
+		// an empty line forces evaluation of incomplete input so the user can see what is wrong
+		if line != "" || input.Len() == 0 {
+			if input.Len() > 0 {
+				input.WriteString("\n")
+			}
+			input.WriteString(line)
+
+			if isIncomplete(input.String()) {
+				continue
+			}
+		}
+
+		src := input.String()
+		input.Reset()
+
+		if evaluated, _ := s.eval(lexer.New(src), src); evaluated != nil {
+			io.WriteString(out, s.printer.format(evaluated))
 			io.WriteString(out, "\n")
 		}
 	}
 }
 
-func printParserErrors(out io.Writer, errors []string) {
+// newEnvironment returns an empty environment in which puts writes to the output of the session
+func (s *session) newEnvironment() *object.Environment {
+	env := object.NewEnvironment()
+	env.SetOutput(s.out)
+	return env
+}
+
+// eval parses and evaluates the source in the session environment, it reports false if the source
+// could not be parsed and the parser errors have been printed
+func (s *session) eval(lex *lexer.Lexer, src string) (object.Object, bool) {
+	p := parser.New(lex)
+
+	program := p.ParseProgram()
+	if len(p.ParseErrors()) != 0 {
+		printParserErrors(s.out, src, p.ParseErrors())
+		return nil, false
+	}
+
+	return evaluator.Eval(program, s.env), true
+}
+
+func printParserErrors(out io.Writer, source string, errors []*parser.ParseError) {
 	io.WriteString(out, MONKEY_FACE)
 	io.WriteString(out, "Woops! We ran into some monkey business here!\n")
 	io.WriteString(out, " parser errors:\n")
-	for _, msg := range errors {
-		io.WriteString(out, "\t"+msg+"\n")
-	}
+	parser.RenderErrors(out, source, errors)
 }
@@ -0,0 +1,224 @@
+package repl
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"monkey/lexer"
+	"monkey/object"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type Suite struct {
+	suite.Suite
+}
+
+func (s *Suite) SetupTest() {
+}
+
+func TestRunSuite(t *testing.T) {
+	suite.Run(t, new(Suite))
+}
+
+func (s *Suite) TestIsIncomplete() {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"let x = 5;", false},
+		{"let f = fn(x) {", true},
+		{"let f = fn(x) {\n x + 1\n}", false},
+		{"add(1,", true},
+		{"add(1, 2", true},
+		{"[1, 2,\n 3", true},
+		{`{"a": 1,`, true},
+		{"1 +", true},
+		{"let x =", true},
+		{"if (x) { 1 } else", true},
+		{`"unterminated`, true},
+		{`"done"`, false},
+		{`""`, false},
+		{"}", false},
+		{"", false},
+		{"1 /* comment", true},
+		{"1 /* a /* b */", true},
+		{"1 /* a /* b */ */", false},
+		{"1 // comment", false},
+		{"x &&", true},
+		{"x +=", true},
+		{"2 **", true},
+		{"1 <=", true},
+		{`"escaped \"`, true},
+		{`"escaped \""`, false},
+		{"`raw", true},
+		{"`raw\n`", false},
+		{`"bad \q"`, false},
+	}
+
+	for _, tt := range tests {
+		s.Require().Equal(tt.expected, isIncomplete(tt.input), tt.input)
+	}
+}
+
+func (s *Suite) TestMultiLineInput() {
+	input := strings.Join([]string{
+		"let add = fn(x, y) {",
+		"  x +",
+		"  y",
+		"};",
+		"add(1, 2)",
+		"(1 +",
+		"",
+	}, "\n") + "\n"
+
+	var out bytes.Buffer
+	Start(strings.NewReader(input), &out)
+
+	output := out.String()
+	s.Require().True(strings.HasPrefix(output, ">>......>>3\n>>.."+MONKEY_FACE), output)
+	s.Require().Contains(output, "1:5: no prefix parse function for EOF found")
+	s.Require().True(strings.HasSuffix(output, ">>"), output)
+}
+
+func (s *Suite) TestPutsWritesToOutput() {
+	var out bytes.Buffer
+	Start(strings.NewReader("puts(\"hi\")\n:reset\nputs(1)\n"), &out)
+
+	s.Require().Equal(">>hi\nnull\n>>>>1\nnull\n>>", out.String())
+}
+
+func (s *Suite) TestCommands() {
+	script := filepath.Join(s.T().TempDir(), "lib.mk")
+	s.Require().NoError(os.WriteFile(script, []byte(`let double = fn(x) { x * 2 };`), 0o644))
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{":tokens let x = 1", "1:1    LET        \"let\"\n1:5    IDENT      \"x\"\n1:7    =          \"=\"\n1:9    INT        \"1\"\n1:10   EOF        \"\"\n"},
+		{":ast x + 1", "Program 1:1-1:6\n  Statements: [1]\n    0: ExpressionStatement 1:1-1:6\n      Expression: InfixExpression 1:1-1:6\n        Left: Identifier 1:1-1:2\n          Value: \"x\"\n        Operator: \"+\"\n        Right: IntegerLiteral 1:5-1:6\n          Value: 1\n"},
+		{":type 1 + 1", "INTEGER\n"},
+		{":type let x = 1", "NULL\n"},
+		{":load " + script, "loaded " + script + "\n"},
+		{"let a = double(2)", ""},
+		{":env", "a: INTEGER = 4\ndouble: FUNCTION = fn(x) { ...\nx: INTEGER = 1\n"},
+		{":reset", ""},
+		{":env", ""},
+		{":nope", "unknown command :nope, type :help for a list of commands\n"},
+		{":load", "usage: :load <file>\n"},
+	}
+
+	session := &session{env: object.NewEnvironment()}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		session.out = &out
+
+		if isCommand(tt.input) {
+			session.runCommand(tt.input)
+		} else {
+			session.eval(lexer.New(tt.input), tt.input)
+		}
+
+		s.Require().Equal(tt.expected, out.String(), tt.input)
+	}
+}
+
+func (s *Suite) TestHelpCommand() {
+	var out bytes.Buffer
+	Start(strings.NewReader(":help\n"), &out)
+
+	for _, cmd := range commands {
+		s.Require().Contains(out.String(), cmd.usage)
+	}
+}
+
+func (s *Suite) TestComplete() {
+	session := &session{env: object.NewEnvironment()}
+	session.env.Set("length", &object.Integer{Value: 1})
+
+	tests := []struct {
+		line          string
+		expected      []string
+		expectedStart int
+	}{
+		{"le", []string{"len", "length", "let"}, 0},
+		{"x + fi", []string{"first"}, 4},
+		{"push(ar", []string{}, 5},
+		{":lo", []string{"load"}, 1},
+		{"re", []string{"rest", "return"}, 0},
+	}
+
+	for _, tt := range tests {
+		line := []rune(tt.line)
+		candidates, start := session.complete(line, len(line))
+
+		s.Require().Equal(tt.expected, candidates, tt.line)
+		s.Require().Equal(tt.expectedStart, start, tt.line)
+	}
+}
+
+func (s *Suite) TestHighlight() {
+	input := `let s = "hi" + 1; # x`
+	expected := colorMagenta + "let" + colorReset + " s " + colorCyan + "=" + colorReset + " " +
+		colorGreen + `"hi"` + colorReset + " " + colorCyan + "+" + colorReset + " " +
+		colorYellow + "1" + colorReset + "; " + colorRed + "#" + colorReset + " x"
+
+	s.Require().Equal(expected, highlight(input))
+	s.Require().Equal("", highlight(""))
+
+	input = "x /* c */ // d"
+	expected = "x " + colorGray + "/* c */" + colorReset + " " + colorGray + "// d" + colorReset
+	s.Require().Equal(expected, highlight(input))
+}
+
+func (s *Suite) TestPrettyPrint() {
+	env := object.NewEnvironment()
+	session := &session{env: env}
+
+	eval := func(input string) object.Object {
+		evaluated, ok := session.eval(lexer.New(input), input)
+		s.Require().True(ok, input)
+		return evaluated
+	}
+
+	p := &printer{width: 30}
+
+	s.Require().Equal("hello", p.format(eval(`"hello"`)))
+	s.Require().Equal(`[1, "two", [true, null]]`, p.format(eval(`[1, "two", [true, if (false) { 1 }]]`)))
+	s.Require().Equal(`{"a": [1, 2]}`, p.format(eval(`{"a": [1, 2]}`)))
+
+	expected := `{
+  "numbers": [1, 2, 3, 4, 5, 6],
+  "words": [
+    "alpha",
+    "beta",
+    "gamma",
+    "delta",
+  ],
+  "empty": [],
+}`
+	s.Require().Equal(expected, p.format(eval(`{
+		"numbers": [1, 2, 3, 4, 5, 6],
+		"words": ["alpha", "beta", "gamma", "delta"],
+		"empty": [],
+	}`)))
+
+	s.Require().Equal(`[1, [...]]`, p.format(eval(`let a = [1, 2]; a[1] = a; a`)))
+	s.Require().Equal(`{"a": {...}, "b": [{...}]}`, p.format(eval(`let h = {"a": 1}; h["a"] = h; h["b"] = [h]; h`)))
+
+	colored := &printer{color: true, width: MAX_WIDTH}
+	s.Require().Equal("["+colorYellow+"1"+colorReset+", "+colorGreen+`"a"`+colorReset+"]", colored.format(eval(`[1, "a"]`)))
+	s.Require().Equal(colorRed+"ERROR: identifier not found: x"+colorReset, colored.format(eval(`x`)))
+}
+
+func (s *Suite) TestColorEnabled() {
+	s.Require().False(ColorEnabled(&bytes.Buffer{}))
+
+	s.T().Setenv("NO_COLOR", "1")
+	s.Require().False(ColorEnabled(os.Stdout))
+}
@@ -0,0 +1,29 @@
+package token
+
+import "fmt"
+
+// Position describes a location in the source code
+type Position struct {
+	Filename string // name of the source file, may be empty
+	Offset   int    // byte offset, starting at 0
+	Line     int    // line number, starting at 1
+	Column   int    // column number, starting at 1
+}
+
+// IsValid reports whether the position has been set by the lexer
+func (p Position) IsValid() bool { return p.Line > 0 }
+
+// String returns the position in the form file:line:column, line:column or "-" if the position is not valid
+func (p Position) String() string {
+	s := p.Filename
+	if p.IsValid() {
+		if s != "" {
+			s += ":"
+		}
+		s += fmt.Sprintf("%d:%d", p.Line, p.Column)
+	}
+	if s == "" {
+		s = "-"
+	}
+	return s
+}